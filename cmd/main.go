@@ -33,19 +33,43 @@ func main() {
 	}
 
 	dockerService := services.NewDockerService(dockerClient)
+	crashLoopDetector := services.NewCrashLoopDetector(dockerService, config)
+	go crashLoopDetector.Run(context.Background())
+
+	serviceRepository := repositories.NewServiceRepository(dbPool)
+	portAllocationRepository := repositories.NewPortAllocationRepository(dbPool)
+	serviceService := services.NewServiceService(
+		serviceRepository,
+		portAllocationRepository,
+		dockerService,
+		crashLoopDetector,
+		config,
+	)
+	serviceController := controllers.NewServiceController(serviceService)
+	execController := controllers.NewExecController(serviceService, config)
 
 	projectRepository := repositories.NewProjectRepository(dbPool)
-	projectService := services.NewProjectService(projectRepository)
+	projectService := services.NewProjectService(projectRepository, serviceRepository, serviceService, dockerService)
 	projectController := controllers.NewProjectController(projectService)
 
-	serviceRepository := repositories.NewServiceRepository(dbPool)
-	serviceService := services.NewServiceService(serviceRepository, dockerService)
-	serviceController := controllers.NewServiceController(serviceService)
+	volumeService := services.NewVolumeService(projectRepository, serviceRepository, dockerService, config)
+	volumeController := controllers.NewVolumeController(volumeService)
+
+	statsRepository := repositories.NewStatsRepository(dbPool)
+	statsService := services.NewStatsService(
+		projectRepository,
+		serviceRepository,
+		statsRepository,
+		dockerService,
+		config,
+	)
+	statsController := controllers.NewStatsController(statsService)
+	go statsService.Run(context.Background())
 
 	router := gin.New()
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:5173"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowOrigins:     config.AllowedOrigins,
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: false,
@@ -57,24 +81,40 @@ func main() {
 	projectGroup.GET("/:id", projectController.GetByID)
 	projectGroup.PATCH("/:id", projectController.UpdateByID)
 	projectGroup.DELETE("/:id", projectController.DeleteByID)
-
-	// TODO: get overall status of project (e.g., are all services running?)
+	projectGroup.POST("/:id/start", projectController.Start)
+	projectGroup.POST("/:id/stop", projectController.Stop)
+	projectGroup.POST("/:id/restart", projectController.Restart)
+	projectGroup.GET("/:id/status", projectController.GetStatus)
+	projectGroup.GET("/:id/logs", projectController.StreamLogs)
+	projectGroup.GET("/:id/stats/history", statsController.GetProjectHistory)
+	projectGroup.GET("/:id/volumes", volumeController.ListAll)
+	projectGroup.POST("/:id/volumes", volumeController.Create)
+	projectGroup.DELETE("/:id/volumes/:name", volumeController.DeleteByName)
 
 	serviceGroup := router.Group("/services")
 	serviceGroup.GET("/", serviceController.ListAll)
 	serviceGroup.POST("/", serviceController.Create) // TODO: review required
+	serviceGroup.POST("/status", serviceController.GetBatchStatus)
 	serviceGroup.GET("/:id", serviceController.GetByID)
+	serviceGroup.PATCH("/:id", serviceController.UpdateByID)
+	serviceGroup.DELETE("/:id", serviceController.DeleteByID)
 	serviceGroup.POST("/:id/start", serviceController.Start)
 	serviceGroup.POST("/:id/stop", serviceController.Stop)
+	serviceGroup.POST("/:id/restart", serviceController.Restart)
+	serviceGroup.POST("/:id/pause", serviceController.Pause)
+	serviceGroup.POST("/:id/unpause", serviceController.Unpause)
 	serviceGroup.GET("/:id/status", serviceController.GetStatus)
 	serviceGroup.GET("/:id/logs", serviceController.StreamLogs)
-	// TODO: batch service status report
-	// TODO: pause/unpause service
-	// TODO: update service
-	// TODO: delete service
-	// TODO: restart service
-	// TODO: get service health
-	// TODO: get service container information
+	serviceGroup.GET("/:id/stats", serviceController.GetStats)
+	serviceGroup.GET("/:id/stats/history", statsController.GetServiceHistory)
+	serviceGroup.GET("/:id/container", serviceController.GetContainer)
+	serviceGroup.GET("/:id/health", serviceController.GetHealth)
+	serviceGroup.GET("/:id/exec", execController.Terminal)
+	serviceGroup.POST("/:id/run", execController.Run)
+
+	volumeGroup := router.Group("/volumes")
+	volumeGroup.POST("/:name/backup", volumeController.Backup)
+	volumeGroup.POST("/:name/restore", volumeController.Restore)
 
 	if err := router.Run(); err != nil {
 		log.Fatal().Err(err).Msg("failed to start server")
@@ -1,6 +1,9 @@
 package internal
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 )
@@ -9,6 +12,35 @@ import (
 type AppConfig struct {
 	// DatabaseURL is the connection string (DSN) for the PostgreSQL database.
 	DatabaseURL string `envconfig:"database_url"`
+	// AllowedOrigins is a comma-separated list of origins allowed to access
+	// the API from a browser, including WebSocket connections.
+	AllowedOrigins []string `envconfig:"allowed_origins" default:"http://localhost:5173"`
+	// DependencyTimeout is the maximum time to wait for a single dependency
+	// to satisfy its condition when starting a service.
+	DependencyTimeout time.Duration `envconfig:"dependency_timeout" default:"60s"`
+	// AllowedBindPaths is a comma-separated list of host path prefixes that
+	// services may bind mount. Bind mounts are rejected when it is empty.
+	AllowedBindPaths []string `envconfig:"allowed_bind_paths"`
+	// VolumeHelperImage is the image of the short-lived helper containers used
+	// to back up and restore volumes. It must provide `find`.
+	VolumeHelperImage string `envconfig:"volume_helper_image" default:"alpine:3"`
+	// PortRangeStart is the first host port that may be allocated to published
+	// ports without an explicit host port.
+	PortRangeStart int `envconfig:"port_range_start" default:"20000"`
+	// PortRangeEnd is the last host port that may be allocated to published
+	// ports without an explicit host port.
+	PortRangeEnd int `envconfig:"port_range_end" default:"29999"`
+	// StatsInterval is the interval at which resource usage samples of all
+	// service containers are recorded. Zero disables recording.
+	StatsInterval time.Duration `envconfig:"stats_interval" default:"30s"`
+	// StatsRetention is how long recorded samples are kept.
+	StatsRetention time.Duration `envconfig:"stats_retention" default:"168h"`
+	// CrashLoopRestarts is the number of crashes within `CrashLoopWindow`
+	// after which a service is reported as crash looping. Zero disables the
+	// detection.
+	CrashLoopRestarts int `envconfig:"crash_loop_restarts" default:"5"`
+	// CrashLoopWindow is the period in which crashes are counted.
+	CrashLoopWindow time.Duration `envconfig:"crash_loop_window" default:"10m"`
 }
 
 // LoadConfig loads the application configuration from environment variables.
@@ -22,5 +54,8 @@ func LoadConfig() (*AppConfig, error) {
 	if err := envconfig.Process("gidock", &config); err != nil {
 		return nil, err
 	}
+	if config.PortRangeStart < 1 || config.PortRangeEnd > 65535 || config.PortRangeStart > config.PortRangeEnd {
+		return nil, fmt.Errorf("invalid host port range %d-%d", config.PortRangeStart, config.PortRangeEnd)
+	}
 	return &config, nil
 }
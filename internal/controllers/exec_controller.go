@@ -0,0 +1,356 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Pelfox/gidock/internal"
+	"github.com/Pelfox/gidock/internal/dto"
+	"github.com/Pelfox/gidock/internal/services"
+	"github.com/Pelfox/gidock/pkg"
+	"github.com/containerd/errdefs"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultExecCommand is the command of a terminal session, unless the client
+// requests another one.
+var defaultExecCommand = []string{"/bin/sh"}
+
+const (
+	// terminalWriteTimeout is the maximum time to write a single message to
+	// the client of a terminal session.
+	terminalWriteTimeout = 10 * time.Second
+	// defaultRunTimeout is the timeout of a one-off command, unless the client
+	// requests another one.
+	defaultRunTimeout = 60
+	// maxRunTimeout is the maximum timeout of a one-off command in seconds.
+	maxRunTimeout = 3600
+	// maxRunOutput is the maximum number of bytes of each output stream of a
+	// one-off command returned as JSON.
+	maxRunOutput = 1 << 20
+)
+
+type ExecController struct {
+	serviceService *services.ServiceService
+	upgrader       websocket.Upgrader
+}
+
+func NewExecController(serviceService *services.ServiceService, config *internal.AppConfig) *ExecController {
+	return &ExecController{
+		serviceService: serviceService,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  4096,
+			WriteBufferSize: 4096,
+			// browsers allow cross-origin WebSocket connections, so only
+			// trusted origins may open a terminal
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || slices.Contains(config.AllowedOrigins, origin)
+			},
+		},
+	}
+}
+
+func (c *ExecController) Terminal(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided service ID is invalid."})
+		return
+	}
+
+	command := ctx.QueryArray("cmd")
+	if len(command) == 0 {
+		command = defaultExecCommand
+	}
+	rows, err := strconv.ParseUint(ctx.DefaultQuery("rows", "0"), 10, 16)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `rows` value is invalid."})
+		return
+	}
+	cols, err := strconv.ParseUint(ctx.DefaultQuery("cols", "0"), 10, 16)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `cols` value is invalid."})
+		return
+	}
+
+	// the origin is checked while upgrading, so nothing may be started in
+	// the container before
+	conn, err := c.upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		// the upgrader has already responded with an error
+		log.Warn().Err(err).Msg("failed to upgrade terminal connection")
+		return
+	}
+	defer conn.Close()
+
+	// the session outlives the request context once the connection is
+	// upgraded, so it is bound to the WebSocket instead
+	session, err := c.serviceService.Exec(context.WithoutCancel(ctx.Request.Context()), id, command, uint(rows), uint(cols))
+	if err != nil {
+		closeWithExecError(conn, err)
+		return
+	}
+	defer session.Close()
+
+	outputDone := make(chan struct{})
+	go func() {
+		defer close(outputDone)
+		c.forwardOutput(conn, session)
+	}()
+
+	c.forwardInput(conn, session)
+	// hang up the process, so the output ends as well
+	session.Close()
+	<-outputDone
+}
+
+// forwardOutput sends the terminal output to the client as binary messages.
+// Once the process exits, the connection is closed with its exit code as the
+// reason.
+func (c *ExecController) forwardOutput(conn *websocket.Conn, session *services.ExecSession) {
+	buffer := make([]byte, 32*1024)
+	for {
+		n, err := session.Read(buffer)
+		if n > 0 {
+			_ = conn.SetWriteDeadline(time.Now().Add(terminalWriteTimeout))
+			if writeErr := conn.WriteMessage(websocket.BinaryMessage, buffer[:n]); writeErr != nil {
+				return
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	reason := "session closed"
+	ctx, cancel := context.WithTimeout(context.Background(), terminalWriteTimeout)
+	defer cancel()
+	if exitCode, err := session.ExitCode(ctx); err == nil {
+		reason = fmt.Sprintf("exit code %d", exitCode)
+	}
+	closeMessage := websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason)
+	_ = conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(terminalWriteTimeout))
+}
+
+// forwardInput passes the messages of the client to the terminal until the
+// client disconnects. Binary messages are raw input, text messages are
+// `dto.TerminalMessage` control messages.
+func (c *ExecController) forwardInput(conn *websocket.Conn, session *services.ExecSession) {
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		if messageType == websocket.BinaryMessage {
+			if _, err := session.Write(data); err != nil {
+				return
+			}
+			continue
+		}
+
+		var message dto.TerminalMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			log.Debug().Err(err).Msg("ignoring invalid terminal message")
+			continue
+		}
+		switch message.Type {
+		case dto.TerminalMessageInput:
+			if _, err := io.WriteString(session, message.Data); err != nil {
+				return
+			}
+		case dto.TerminalMessageResize:
+			ctx, cancel := context.WithTimeout(context.Background(), terminalWriteTimeout)
+			err := session.Resize(ctx, message.Rows, message.Cols)
+			cancel()
+			if err != nil {
+				log.Debug().Err(err).Msg("failed to resize terminal")
+			}
+		default:
+			log.Debug().Str("type", string(message.Type)).Msg("ignoring unknown terminal message")
+		}
+	}
+}
+
+func (c *ExecController) Run(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided service ID is invalid."})
+		return
+	}
+
+	stream, err := strconv.ParseBool(ctx.DefaultQuery("stream", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `stream` flag is invalid."})
+		return
+	}
+
+	var request dto.RunCommandRequest
+	if err := ctx.BindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request body."})
+		return
+	}
+	if request.Timeout == 0 {
+		request.Timeout = defaultRunTimeout
+	}
+	if request.Timeout < 0 || request.Timeout > maxRunTimeout {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": fmt.Sprintf("The timeout must be between 1 and %d seconds.", maxRunTimeout),
+		})
+		return
+	}
+
+	session, err := c.serviceService.StartCommand(ctx.Request.Context(), id, request)
+	if err != nil {
+		handleExecError(ctx, err)
+		return
+	}
+	defer session.Close()
+
+	runCtx, cancel := context.WithTimeout(ctx.Request.Context(), time.Duration(request.Timeout)*time.Second)
+	defer cancel()
+
+	if stream {
+		c.streamRun(ctx, runCtx, session)
+		return
+	}
+
+	stdout := &limitedBuffer{limit: maxRunOutput}
+	stderr := &limitedBuffer{limit: maxRunOutput}
+	exitCode, err := session.Wait(runCtx, stdout, stderr)
+	response := dto.RunCommandResponse{
+		ExitCode:  exitCode,
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		Truncated: stdout.truncated || stderr.truncated,
+	}
+	switch {
+	case ctx.Request.Context().Err() != nil:
+		// the client hung up, so the run didn't time out
+		log.Debug().Err(err).Msg("client disconnected while running command")
+		return
+	case errors.Is(err, internal.ErrCommandTimeout):
+		response.ExitCode = -1
+		response.TimedOut = true
+		ctx.JSON(http.StatusGatewayTimeout, response)
+		return
+	case err != nil:
+		log.Error().Err(err).Msg("failed to run command in service container")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to run command in service container."})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// streamRun sends the output of the command as `stdout` and `stderr` SSE
+// events, followed by an `exit` event once it exits or times out.
+func (c *ExecController) streamRun(ctx *gin.Context, runCtx context.Context, session *services.ExecSession) {
+	conn := pkg.NewSSEConn(ctx, 10*time.Second)
+	conn.SetupHeaders()
+	conn.StartHeartbeats()
+	defer conn.Close()
+
+	exitCode, err := session.Wait(
+		runCtx,
+		&sseOutputWriter{conn: conn, event: "stdout"},
+		&sseOutputWriter{conn: conn, event: "stderr"},
+	)
+	exit := dto.RunExitEvent{ExitCode: exitCode}
+	switch {
+	case ctx.Request.Context().Err() != nil:
+		// the client hung up, so the run didn't time out
+		log.Debug().Err(err).Msg("client disconnected while streaming command")
+		return
+	case errors.Is(err, internal.ErrCommandTimeout):
+		exit.ExitCode = -1
+		exit.TimedOut = true
+	case err != nil:
+		log.Error().Err(err).Msg("failed to run command in service container")
+		_ = conn.SendEvent("error", gin.H{"message": "Failed to run command in service container."})
+		return
+	}
+
+	if err := conn.SendEvent("exit", exit); err != nil {
+		log.Error().Err(err).Msg("failed to send exit event")
+	}
+}
+
+// sseOutputWriter sends every write as an SSE event with the output chunk.
+type sseOutputWriter struct {
+	conn  *pkg.SSEConn
+	event string
+}
+
+func (w *sseOutputWriter) Write(p []byte) (int, error) {
+	if err := w.conn.SendEvent(w.event, dto.RunOutputEvent{Data: string(p)}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// limitedBuffer collects up to `limit` bytes and silently drops the rest.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.Len(); len(p) > remaining {
+		b.truncated = true
+		b.Buffer.Write(p[:max(remaining, 0)])
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// handleExecError responds to a failed exec in a service container with an
+// appropriate status code.
+func handleExecError(ctx *gin.Context, err error) {
+	status, message := execError(err)
+	ctx.JSON(status, gin.H{"message": message})
+}
+
+// closeWithExecError closes the WebSocket connection after a failed exec in
+// a service container. The close code is 4000 plus the HTTP status code
+// `handleExecError` would respond with.
+func closeWithExecError(conn *websocket.Conn, err error) {
+	status, message := execError(err)
+	// the reason of a close frame is limited to 123 bytes
+	if len(message) > 123 {
+		message = message[:123]
+	}
+	closeMessage := websocket.FormatCloseMessage(4000+status, message)
+	_ = conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(terminalWriteTimeout))
+}
+
+// execError maps a failed exec in a service container to an HTTP status code
+// and a message for the client.
+func execError(err error) (int, string) {
+	switch {
+	case errors.Is(err, internal.ErrRecordNotFound):
+		return http.StatusNotFound, "Service not found."
+	case errors.Is(err, internal.ErrNoContainer), errdefs.IsNotFound(err):
+		return http.StatusConflict, "Service has no associated container."
+	case errors.Is(err, internal.ErrInvalidCommand):
+		return http.StatusBadRequest, err.Error()
+	case errdefs.IsConflict(err):
+		// the container isn't running
+		return http.StatusConflict, err.Error()
+	default:
+		log.Error().Err(err).Msg("failed to exec in service container")
+		return http.StatusInternalServerError, "Failed to exec in service container."
+	}
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import "testing"
+
+func TestLimitedBuffer(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit         int
+		writes        []string
+		want          string
+		wantTruncated bool
+	}{
+		{name: "below limit", limit: 10, writes: []string{"abc", "def"}, want: "abcdef"},
+		{name: "exactly at limit", limit: 6, writes: []string{"abc", "def"}, want: "abcdef"},
+		{name: "write crossing limit", limit: 4, writes: []string{"abc", "def"}, want: "abcd", wantTruncated: true},
+		{name: "writes after limit", limit: 3, writes: []string{"abc", "def", "ghi"}, want: "abc", wantTruncated: true},
+		{name: "zero limit", limit: 0, writes: []string{"abc"}, want: "", wantTruncated: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buffer := &limitedBuffer{limit: test.limit}
+			for _, write := range test.writes {
+				// dropped output must not fail the writer
+				if n, err := buffer.Write([]byte(write)); n != len(write) || err != nil {
+					t.Fatalf("Write(%q) = %d, %v, want %d, nil", write, n, err, len(write))
+				}
+			}
+			if got := buffer.String(); got != test.want {
+				t.Errorf("String() = %q, want %q", got, test.want)
+			}
+			if buffer.truncated != test.wantTruncated {
+				t.Errorf("truncated = %v, want %v", buffer.truncated, test.wantTruncated)
+			}
+		})
+	}
+}
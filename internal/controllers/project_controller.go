@@ -1,11 +1,16 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Pelfox/gidock/internal"
 	"github.com/Pelfox/gidock/internal/dto"
 	"github.com/Pelfox/gidock/internal/services"
+	"github.com/Pelfox/gidock/pkg"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
@@ -94,7 +99,18 @@ func (c *ProjectController) DeleteByID(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.projectService.Delete(ctx.Request.Context(), id); err != nil {
+	removeVolumes, err := strconv.ParseBool(ctx.DefaultQuery("volumes", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `volumes` flag is invalid."})
+		return
+	}
+
+	err = c.projectService.Delete(ctx.Request.Context(), id, removeVolumes)
+	if errors.Is(err, internal.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Project not found."})
+		return
+	}
+	if err != nil {
 		log.Error().Err(err).Msg("failed to delete project")
 		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete project."})
 		return
@@ -112,3 +128,159 @@ func (c *ProjectController) ListAll(ctx *gin.Context) {
 	}
 	ctx.JSON(http.StatusOK, projects)
 }
+
+func (c *ProjectController) Start(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided project ID is invalid."})
+		return
+	}
+
+	response, err := c.projectService.Start(ctx.Request.Context(), id)
+	if err != nil {
+		c.handleOperationError(ctx, err, "start")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *ProjectController) Stop(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided project ID is invalid."})
+		return
+	}
+
+	kill, err := strconv.ParseBool(ctx.DefaultQuery("kill", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `kill` flag is invalid."})
+		return
+	}
+
+	response, err := c.projectService.Stop(ctx.Request.Context(), id, kill)
+	if err != nil {
+		c.handleOperationError(ctx, err, "stop")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *ProjectController) Restart(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided project ID is invalid."})
+		return
+	}
+
+	kill, err := strconv.ParseBool(ctx.DefaultQuery("kill", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `kill` flag is invalid."})
+		return
+	}
+
+	response, err := c.projectService.Restart(ctx.Request.Context(), id, kill)
+	if err != nil {
+		c.handleOperationError(ctx, err, "restart")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *ProjectController) GetStatus(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided project ID is invalid."})
+		return
+	}
+
+	status, err := c.projectService.GetStatus(ctx.Request.Context(), id)
+	if errors.Is(err, internal.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Project not found."})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get project status")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to get project status."})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
+
+func (c *ProjectController) StreamLogs(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided project ID is invalid."})
+		return
+	}
+
+	var serviceIDs []uuid.UUID
+	if services := ctx.Query("services"); services != "" {
+		for value := range strings.SplitSeq(services, ",") {
+			serviceID, err := uuid.Parse(strings.TrimSpace(value))
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `services` list is invalid."})
+				return
+			}
+			serviceIDs = append(serviceIDs, serviceID)
+		}
+	}
+
+	request, ok := parseLogsRequest(ctx)
+	if !ok {
+		return
+	}
+
+	logsChannel, err := c.projectService.StreamLogs(ctx.Request.Context(), id, serviceIDs, request)
+	switch {
+	case errors.Is(err, internal.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Project not found."})
+		return
+	case errors.Is(err, internal.ErrServiceNotInProject):
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	case errors.Is(err, internal.ErrNoContainer):
+		ctx.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	case err != nil:
+		log.Error().Err(err).Msg("failed to get project logs")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to get project logs."})
+		return
+	}
+
+	conn := pkg.NewSSEConn(ctx, 10*time.Second)
+	conn.SetupHeaders()
+	conn.StartHeartbeats()
+	defer conn.Close()
+
+	for {
+		select {
+		case entry, ok := <-logsChannel:
+			if !ok {
+				return
+			}
+			if err := conn.SendEvent("log", entry); err != nil {
+				log.Error().Err(err).Msg("failed to send log event")
+			}
+		case <-ctx.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// handleOperationError responds to a failed project-wide operation with an
+// appropriate status code.
+func (c *ProjectController) handleOperationError(ctx *gin.Context, err error, operation string) {
+	switch {
+	case errors.Is(err, internal.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Project not found."})
+	case errors.Is(err, internal.ErrDependencyCycle), errors.Is(err, internal.ErrInvalidDependency):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+	default:
+		log.Error().Err(err).Msgf("failed to %s project", operation)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to " + operation + " project."})
+	}
+}
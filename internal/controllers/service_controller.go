@@ -1,13 +1,16 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/Pelfox/gidock/internal"
 	"github.com/Pelfox/gidock/internal/dto"
 	"github.com/Pelfox/gidock/internal/services"
 	"github.com/Pelfox/gidock/pkg"
+	"github.com/containerd/errdefs"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
@@ -15,7 +18,15 @@ import (
 
 // TODO: make all endpoints return data via response DTOs
 // TODO: handle errors correctly, returning appropriate status codes and messages
-// TODO: add other endpoints (from Service)
+
+const (
+	// defaultLogsTail is the number of log lines returned from the end of the
+	// logs, unless the client requests another one.
+	defaultLogsTail = "200"
+	// maxUnfollowedLogsTail is the maximum number of log lines returned as
+	// JSON when the logs aren't followed.
+	maxUnfollowedLogsTail = 10000
+)
 
 type ServiceController struct {
 	serviceService *services.ServiceService
@@ -33,6 +44,12 @@ func (c *ServiceController) Create(ctx *gin.Context) {
 	}
 
 	service, err := c.serviceService.Create(ctx.Request.Context(), request)
+	if errors.Is(err, internal.ErrInvalidDependency) ||
+		errors.Is(err, internal.ErrDependencyCycle) ||
+		errors.Is(err, internal.ErrInvalidServiceSpec) {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+		return
+	}
 	if err != nil {
 		log.Error().Err(err).Msg("failed to create service")
 		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create service."})
@@ -60,6 +77,80 @@ func (c *ServiceController) GetByID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, service)
 }
 
+func (c *ServiceController) UpdateByID(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided service ID is invalid."})
+		return
+	}
+
+	apply, err := strconv.ParseBool(ctx.DefaultQuery("apply", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `apply` flag is invalid."})
+		return
+	}
+
+	var request dto.UpdateServiceRequest
+	if err := ctx.BindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request body."})
+		return
+	}
+
+	service, err := c.serviceService.Update(ctx.Request.Context(), id, request, apply)
+	switch {
+	case errors.Is(err, internal.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Service not found."})
+		return
+	case errors.Is(err, internal.ErrNoFields):
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "No fields to update were provided."})
+		return
+	case errors.Is(err, internal.ErrInvalidDependency),
+		errors.Is(err, internal.ErrDependencyCycle),
+		errors.Is(err, internal.ErrInvalidServiceSpec):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+		return
+	case errors.Is(err, internal.ErrPortConflict), errors.Is(err, internal.ErrNoFreePort):
+		ctx.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	case err != nil:
+		log.Error().Err(err).Msg("failed to update service")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update service."})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, service)
+}
+
+func (c *ServiceController) DeleteByID(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided service ID is invalid."})
+		return
+	}
+
+	removeVolumes, err := strconv.ParseBool(ctx.DefaultQuery("volumes", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `volumes` flag is invalid."})
+		return
+	}
+
+	err = c.serviceService.Delete(ctx.Request.Context(), id, removeVolumes)
+	switch {
+	case errors.Is(err, internal.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Service not found."})
+		return
+	case errors.Is(err, internal.ErrServiceInUse):
+		ctx.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	case err != nil:
+		log.Error().Err(err).Msg("failed to delete service")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete service."})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
 func (c *ServiceController) ListAll(ctx *gin.Context) {
 	servicesList, err := c.serviceService.ListAll(ctx.Request.Context())
 	if err != nil {
@@ -84,6 +175,18 @@ func (c *ServiceController) Start(ctx *gin.Context) {
 	}
 
 	service, err := c.serviceService.Start(ctx.Request.Context(), id, forcePull)
+	if errors.Is(err, internal.ErrDependencyCycle) {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+		return
+	}
+	if errors.Is(err, internal.ErrDependencyNotReady) {
+		ctx.JSON(http.StatusFailedDependency, gin.H{"message": err.Error()})
+		return
+	}
+	if errors.Is(err, internal.ErrPortConflict) || errors.Is(err, internal.ErrNoFreePort) {
+		ctx.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	}
 	if err != nil {
 		log.Error().Err(err).Msg("failed to start service")
 		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to start service."})
@@ -115,6 +218,68 @@ func (c *ServiceController) Stop(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": "OK."})
 }
 
+func (c *ServiceController) Restart(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided service ID is invalid."})
+		return
+	}
+
+	kill, err := strconv.ParseBool(ctx.DefaultQuery("kill", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `kill` flag is invalid."})
+		return
+	}
+
+	var timeout *int
+	if rawTimeout, ok := ctx.GetQuery("timeout"); ok {
+		parsedTimeout, err := strconv.Atoi(rawTimeout)
+		if err != nil || parsedTimeout < -1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `timeout` is invalid."})
+			return
+		}
+		timeout = &parsedTimeout
+	}
+
+	err = c.serviceService.Restart(ctx.Request.Context(), id, timeout, kill)
+	if err != nil {
+		c.handleContainerOperationError(ctx, err, "restart")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "OK."})
+}
+
+func (c *ServiceController) Pause(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided service ID is invalid."})
+		return
+	}
+
+	if err = c.serviceService.Pause(ctx.Request.Context(), id); err != nil {
+		c.handleContainerOperationError(ctx, err, "pause")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "OK."})
+}
+
+func (c *ServiceController) Unpause(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided service ID is invalid."})
+		return
+	}
+
+	if err = c.serviceService.Unpause(ctx.Request.Context(), id); err != nil {
+		c.handleContainerOperationError(ctx, err, "unpause")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "OK."})
+}
+
 func (c *ServiceController) GetStatus(ctx *gin.Context) {
 	id, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
@@ -132,6 +297,84 @@ func (c *ServiceController) GetStatus(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, status)
 }
 
+func (c *ServiceController) GetContainer(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided service ID is invalid."})
+		return
+	}
+
+	containerInfo, err := c.serviceService.GetContainer(ctx.Request.Context(), id)
+	switch {
+	case errors.Is(err, internal.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Service not found."})
+		return
+	case errors.Is(err, internal.ErrNoContainer), errdefs.IsNotFound(err):
+		ctx.JSON(http.StatusConflict, gin.H{"message": "Service has no associated container."})
+		return
+	case err != nil:
+		log.Error().Err(err).Msg("failed to get service container")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to get service container."})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, containerInfo)
+}
+
+func (c *ServiceController) GetHealth(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided service ID is invalid."})
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "5"))
+	if err != nil || limit < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `limit` is invalid."})
+		return
+	}
+
+	health, err := c.serviceService.GetHealth(ctx.Request.Context(), id, limit)
+	switch {
+	case errors.Is(err, internal.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Service not found."})
+		return
+	case errors.Is(err, internal.ErrNoContainer), errdefs.IsNotFound(err):
+		ctx.JSON(http.StatusConflict, gin.H{"message": "Service has no associated container."})
+		return
+	case errors.Is(err, internal.ErrNoHealthCheck):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Service container has no health check configured."})
+		return
+	case err != nil:
+		log.Error().Err(err).Msg("failed to get service health")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to get service health."})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, health)
+}
+
+func (c *ServiceController) GetBatchStatus(ctx *gin.Context) {
+	var request dto.BatchServiceStatusRequest
+	if err := ctx.BindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request body."})
+		return
+	}
+	if len(request.ServiceIDs) == 0 && request.ProjectID == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "Either `service_ids` or `project_id` must be provided."})
+		return
+	}
+
+	statuses, err := c.serviceService.GetBatchStatus(ctx.Request.Context(), request)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get batch service status")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to get service statuses."})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statuses)
+}
+
 func (c *ServiceController) StreamLogs(ctx *gin.Context) {
 	id, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
@@ -139,13 +382,27 @@ func (c *ServiceController) StreamLogs(ctx *gin.Context) {
 		return
 	}
 
-	logsChannel, err := c.serviceService.StreamLogs(ctx.Request.Context(), id)
+	request, ok := parseLogsRequest(ctx)
+	if !ok {
+		return
+	}
+
+	logsChannel, err := c.serviceService.StreamLogs(ctx.Request.Context(), id, request)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get service logs")
 		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to get service logs."})
 		return
 	}
 
+	if !request.Follow {
+		response := dto.ServiceLogsResponse{Entries: make([]pkg.LogEntry, 0)}
+		for entry := range logsChannel {
+			response.Entries = append(response.Entries, entry)
+		}
+		ctx.JSON(http.StatusOK, response)
+		return
+	}
+
 	conn := pkg.NewSSEConn(ctx, 10*time.Second)
 	conn.SetupHeaders()
 	conn.StartHeartbeats()
@@ -165,3 +422,137 @@ func (c *ServiceController) StreamLogs(ctx *gin.Context) {
 		}
 	}
 }
+
+func (c *ServiceController) GetStats(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided service ID is invalid."})
+		return
+	}
+
+	once, err := strconv.ParseBool(ctx.DefaultQuery("once", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `once` flag is invalid."})
+		return
+	}
+
+	if once {
+		stats, err := c.serviceService.GetStats(ctx.Request.Context(), id)
+		if err != nil {
+			c.handleContainerOperationError(ctx, err, "get stats of")
+			return
+		}
+		ctx.JSON(http.StatusOK, stats)
+		return
+	}
+
+	statsChannel, err := c.serviceService.StreamStats(ctx.Request.Context(), id)
+	if err != nil {
+		c.handleContainerOperationError(ctx, err, "get stats of")
+		return
+	}
+
+	conn := pkg.NewSSEConn(ctx, 10*time.Second)
+	conn.SetupHeaders()
+	conn.StartHeartbeats()
+	defer conn.Close()
+
+	for {
+		select {
+		case stats, ok := <-statsChannel:
+			if !ok {
+				return
+			}
+			if err := conn.SendEvent("stats", stats); err != nil {
+				log.Error().Err(err).Msg("failed to send stats event")
+			}
+		case <-ctx.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// parseLogsRequest parses the log query parameters (`tail`, `since`, `until`,
+// `follow`, `timestamps` and `stream`). If they are invalid, it responds with
+// an error and returns false.
+func parseLogsRequest(ctx *gin.Context) (dto.ServiceLogsRequest, bool) {
+	request := dto.ServiceLogsRequest{
+		Tail:  ctx.DefaultQuery("tail", defaultLogsTail),
+		Since: ctx.Query("since"),
+		Until: ctx.Query("until"),
+	}
+
+	// `all` is represented as -1
+	tail := -1
+	if request.Tail != "all" {
+		var err error
+		if tail, err = strconv.Atoi(request.Tail); err != nil || tail < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `tail` value is invalid."})
+			return request, false
+		}
+	}
+	if request.Since != "" && !isValidLogsTime(request.Since) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `since` time is invalid."})
+		return request, false
+	}
+	if request.Until != "" && !isValidLogsTime(request.Until) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `until` time is invalid."})
+		return request, false
+	}
+
+	var err error
+	if request.Follow, err = strconv.ParseBool(ctx.DefaultQuery("follow", "true")); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `follow` flag is invalid."})
+		return request, false
+	}
+	// unfollowed logs are collected in memory, so they must be bounded
+	if !request.Follow && (tail < 0 || tail > maxUnfollowedLogsTail) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The `tail` value must not exceed 10000 when `follow` is disabled."})
+		return request, false
+	}
+	if request.Timestamps, err = strconv.ParseBool(ctx.DefaultQuery("timestamps", "true")); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `timestamps` flag is invalid."})
+		return request, false
+	}
+
+	switch stream := pkg.LogStream(ctx.DefaultQuery("stream", "all")); stream {
+	case "all":
+	case pkg.LogStreamStdout, pkg.LogStreamStderr:
+		request.Stream = stream
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `stream` must be `stdout`, `stderr` or `all`."})
+		return request, false
+	}
+
+	return request, true
+}
+
+// isValidLogsTime reports whether the value is a time accepted by Docker for
+// filtering logs: an RFC 3339 time, a Unix timestamp or a duration relative
+// to now.
+func isValidLogsTime(value string) bool {
+	if _, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return true
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return true
+	}
+	_, err := time.ParseDuration(value)
+	return err == nil
+}
+
+// handleContainerOperationError responds to a failed operation on a service
+// container with an appropriate status code.
+func (c *ServiceController) handleContainerOperationError(ctx *gin.Context, err error, operation string) {
+	switch {
+	case errors.Is(err, internal.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Service not found."})
+	case errors.Is(err, internal.ErrNoContainer), errdefs.IsNotFound(err):
+		ctx.JSON(http.StatusConflict, gin.H{"message": "Service has no associated container."})
+	case errdefs.IsConflict(err):
+		ctx.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+	default:
+		log.Error().Err(err).Msgf("failed to %s service", operation)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to " + operation + " service."})
+	}
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Pelfox/gidock/internal"
+	"github.com/Pelfox/gidock/internal/dto"
+	"github.com/Pelfox/gidock/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type StatsController struct {
+	statsService *services.StatsService
+}
+
+func NewStatsController(statsService *services.StatsService) *StatsController {
+	return &StatsController{statsService: statsService}
+}
+
+func (c *StatsController) GetServiceHistory(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided service ID is invalid."})
+		return
+	}
+
+	request, ok := parseStatsHistoryRequest(ctx)
+	if !ok {
+		return
+	}
+
+	history, err := c.statsService.GetServiceHistory(ctx.Request.Context(), id, request)
+	switch {
+	case errors.Is(err, internal.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Service not found."})
+		return
+	case errors.Is(err, internal.ErrInvalidTimeWindow):
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	case err != nil:
+		log.Error().Err(err).Msg("failed to get service stats history")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to get service stats history."})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history)
+}
+
+func (c *StatsController) GetProjectHistory(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided project ID is invalid."})
+		return
+	}
+
+	request, ok := parseStatsHistoryRequest(ctx)
+	if !ok {
+		return
+	}
+
+	history, err := c.statsService.GetProjectHistory(ctx.Request.Context(), id, request)
+	switch {
+	case errors.Is(err, internal.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Project not found."})
+		return
+	case errors.Is(err, internal.ErrInvalidTimeWindow):
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	case err != nil:
+		log.Error().Err(err).Msg("failed to get project stats history")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to get project stats history."})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history)
+}
+
+// parseStatsHistoryRequest parses the time window query parameters. If they
+// are invalid, it responds with an error and returns false.
+func parseStatsHistoryRequest(ctx *gin.Context) (dto.StatsHistoryRequest, bool) {
+	var request dto.StatsHistoryRequest
+
+	if value := ctx.Query("from"); value != "" {
+		from, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `from` time is invalid."})
+			return request, false
+		}
+		request.From = &from
+	}
+	if value := ctx.Query("to"); value != "" {
+		to, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `to` time is invalid."})
+			return request, false
+		}
+		request.To = &to
+	}
+	if value := ctx.Query("step"); value != "" {
+		step, err := time.ParseDuration(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `step` is invalid."})
+			return request, false
+		}
+		request.Step = step
+	}
+
+	return request, true
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Pelfox/gidock/internal"
+	"github.com/Pelfox/gidock/internal/dto"
+	"github.com/Pelfox/gidock/internal/services"
+	"github.com/containerd/errdefs"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type VolumeController struct {
+	volumeService *services.VolumeService
+}
+
+func NewVolumeController(volumeService *services.VolumeService) *VolumeController {
+	return &VolumeController{volumeService: volumeService}
+}
+
+func (c *VolumeController) Create(ctx *gin.Context) {
+	projectID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided project ID is invalid."})
+		return
+	}
+
+	var request dto.CreateVolumeRequest
+	if err := ctx.BindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request body."})
+		return
+	}
+
+	createdVolume, err := c.volumeService.Create(ctx.Request.Context(), projectID, request)
+	switch {
+	case errors.Is(err, internal.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Project not found."})
+		return
+	case errors.Is(err, internal.ErrInvalidVolumeName):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+		return
+	case errors.Is(err, internal.ErrVolumeExists):
+		ctx.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	case err != nil:
+		log.Error().Err(err).Msg("failed to create volume")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create volume."})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createdVolume)
+}
+
+func (c *VolumeController) ListAll(ctx *gin.Context) {
+	projectID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided project ID is invalid."})
+		return
+	}
+
+	volumes, err := c.volumeService.List(ctx.Request.Context(), projectID)
+	if errors.Is(err, internal.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Project not found."})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list volumes")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to list volumes."})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, volumes)
+}
+
+func (c *VolumeController) DeleteByName(ctx *gin.Context) {
+	projectID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided project ID is invalid."})
+		return
+	}
+
+	err = c.volumeService.Delete(ctx.Request.Context(), projectID, ctx.Param("name"))
+	switch {
+	case errors.Is(err, internal.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Project or volume not found."})
+		return
+	case errors.Is(err, internal.ErrVolumeInUse), errdefs.IsConflict(err):
+		ctx.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	case err != nil:
+		log.Error().Err(err).Msg("failed to delete volume")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete volume."})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *VolumeController) Backup(ctx *gin.Context) {
+	name := ctx.Param("name")
+
+	stopServices, err := strconv.ParseBool(ctx.DefaultQuery("stopServices", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `stopServices` flag is invalid."})
+		return
+	}
+
+	archive, err := c.volumeService.Backup(ctx.Request.Context(), name, stopServices)
+	if errors.Is(err, internal.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Volume not found."})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("failed to back up volume")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to back up volume."})
+		return
+	}
+	defer func() {
+		if err := archive.Close(); err != nil {
+			log.Error().Err(err).Str("volume", name).Msg("failed to close volume backup")
+		}
+	}()
+
+	ctx.DataFromReader(http.StatusOK, -1, "application/gzip", archive, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s.tar.gz"`, name),
+	})
+}
+
+func (c *VolumeController) Restore(ctx *gin.Context) {
+	name := ctx.Param("name")
+
+	stopServices, err := strconv.ParseBool(ctx.DefaultQuery("stopServices", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The provided `stopServices` flag is invalid."})
+		return
+	}
+
+	// the archive is either uploaded as the `archive` form file or sent as the raw body
+	var archive io.Reader = ctx.Request.Body
+	if strings.HasPrefix(ctx.ContentType(), "multipart/form-data") {
+		fileHeader, err := ctx.FormFile("archive")
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"message": "The `archive` file is missing."})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"message": "The `archive` file is invalid."})
+			return
+		}
+		defer file.Close()
+		archive = file
+	}
+
+	err = c.volumeService.Restore(ctx.Request.Context(), name, stopServices, archive)
+	if errors.Is(err, internal.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Volume not found."})
+		return
+	}
+	if errors.Is(err, internal.ErrInvalidArchive) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "The archive must be a valid gzip-compressed tar archive."})
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("failed to restore volume")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to restore volume."})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "OK."})
+}
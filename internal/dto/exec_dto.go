@@ -0,0 +1,70 @@
+package dto
+
+// TerminalMessageType is the type of a control message sent by the client
+// of an exec terminal.
+type TerminalMessageType string
+
+const (
+	// TerminalMessageInput carries input for the terminal in `Data`.
+	TerminalMessageInput TerminalMessageType = "input"
+	// TerminalMessageResize changes the terminal size to `Rows` x `Cols`.
+	TerminalMessageResize TerminalMessageType = "resize"
+)
+
+// TerminalMessage is a control message sent by the client of an exec
+// terminal as a text WebSocket message. Binary messages are passed to the
+// terminal as raw input instead.
+type TerminalMessage struct {
+	// Type is the type of the message.
+	Type TerminalMessageType `json:"type"`
+	// Data is the input of an `input` message.
+	Data string `json:"data,omitempty"`
+	// Rows is the terminal height of a `resize` message.
+	Rows uint `json:"rows,omitempty"`
+	// Cols is the terminal width of a `resize` message.
+	Cols uint `json:"cols,omitempty"`
+}
+
+// RunCommandRequest is the request payload for running a one-off command in
+// a service container.
+type RunCommandRequest struct {
+	// Command is the command to execute, e.g. `["./migrate", "up"]`.
+	Command []string `json:"command"`
+	// WorkingDir is the working directory of the command. It defaults to the
+	// working directory of the container.
+	WorkingDir string `json:"working_dir,omitempty"`
+	// Timeout is the number of seconds to wait for the command to exit. It
+	// defaults to 60 seconds.
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// RunCommandResponse is the result of a one-off command.
+type RunCommandResponse struct {
+	// ExitCode is the exit code of the command. It is -1 if the command
+	// timed out.
+	ExitCode int `json:"exit_code"`
+	// TimedOut indicates that the command didn't exit within the timeout.
+	TimedOut bool `json:"timed_out"`
+	// Stdout is the standard output of the command.
+	Stdout string `json:"stdout"`
+	// Stderr is the standard error of the command.
+	Stderr string `json:"stderr"`
+	// Truncated indicates that the output exceeded the size limit and was
+	// cut off.
+	Truncated bool `json:"truncated"`
+}
+
+// RunOutputEvent is a chunk of output of a streamed one-off command.
+type RunOutputEvent struct {
+	// Data is the output chunk.
+	Data string `json:"data"`
+}
+
+// RunExitEvent is the final event of a streamed one-off command.
+type RunExitEvent struct {
+	// ExitCode is the exit code of the command. It is -1 if the command
+	// timed out.
+	ExitCode int `json:"exit_code"`
+	// TimedOut indicates that the command didn't exit within the timeout.
+	TimedOut bool `json:"timed_out"`
+}
@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/Pelfox/gidock/internal/models"
+import (
+	"github.com/Pelfox/gidock/internal/models"
+	"github.com/Pelfox/gidock/pkg"
+	"github.com/google/uuid"
+)
 
 // CreateProjectRequest is the request payload for creating a new project.
 type CreateProjectRequest struct {
@@ -23,3 +27,72 @@ type UpdateProjectRequest struct {
 type UpdateProjectResponse struct {
 	models.Project
 }
+
+// ServiceOperationResult describes the outcome of an operation performed on a
+// single service as part of a project-wide operation.
+type ServiceOperationResult struct {
+	// ServiceID is the unique identifier of the service.
+	ServiceID uuid.UUID `json:"service_id"`
+	// Name is the name of the service.
+	Name string `json:"name"`
+	// Success indicates whether the operation succeeded for this service.
+	Success bool `json:"success"`
+	// Error describes why the operation failed (if it did).
+	Error string `json:"error,omitempty"`
+}
+
+// ProjectOperationResponse is the response payload after performing a
+// project-wide operation (e.g. start, stop or restart).
+type ProjectOperationResponse struct {
+	// Success indicates whether the operation succeeded for every service.
+	Success bool `json:"success"`
+	// Services contains per-service results in the order they were processed.
+	Services []ServiceOperationResult `json:"services"`
+}
+
+// ProjectState is the rolled-up state of all services within a project.
+type ProjectState string
+
+const (
+	// ProjectStateRunning means every service of the project is running.
+	ProjectStateRunning ProjectState = "running"
+	// ProjectStateDegraded means only some services of the project are running.
+	ProjectStateDegraded ProjectState = "degraded"
+	// ProjectStateStopped means the project was deployed, but none of its
+	// services are running.
+	ProjectStateStopped ProjectState = "stopped"
+	// ProjectStateNeverDeployed means none of the project services has a
+	// container yet.
+	ProjectStateNeverDeployed ProjectState = "never_deployed"
+)
+
+// ProjectServiceStatus is the status of a single service within a project.
+type ProjectServiceStatus struct {
+	// ServiceID is the unique identifier of the service.
+	ServiceID uuid.UUID `json:"service_id"`
+	// Name is the name of the service.
+	Name string `json:"name"`
+	// Status is the runtime status of the service container (if available).
+	Status *ServiceStatusResponse `json:"status,omitempty"`
+	// Error describes why the status could not be retrieved (if it couldn't).
+	Error string `json:"error,omitempty"`
+}
+
+// ProjectStatusResponse provides the aggregated runtime status of all
+// services within a project.
+type ProjectStatusResponse struct {
+	// State is the rolled-up state of the project.
+	State ProjectState `json:"state"`
+	// Services contains the status of every service of the project.
+	Services []ProjectServiceStatus `json:"services"`
+}
+
+// ProjectLogEntry is a log entry of a single service within the merged logs
+// of a project.
+type ProjectLogEntry struct {
+	// ServiceID is the unique identifier of the service.
+	ServiceID uuid.UUID `json:"service_id"`
+	// Name is the name of the service.
+	Name string `json:"name"`
+	pkg.LogEntry
+}
@@ -1,9 +1,13 @@
 package dto
 
 import (
+	"time"
+
 	"github.com/Pelfox/gidock/internal/models"
+	"github.com/Pelfox/gidock/pkg"
 	"github.com/google/uuid"
 	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/mount"
 )
 
 // CreateServiceRequest is the request payload for creating a new service.
@@ -22,6 +26,14 @@ type CreateServiceRequest struct {
 	Dependencies []models.ServiceDependency `json:"dependencies"`
 	// NetworkAccess indicates whether the service should be exposed externally.
 	NetworkAccess bool `json:"network_access"`
+	// Ports lists the container ports published on the host.
+	Ports []models.ServicePort `json:"ports"`
+	// HealthCheck optionally defines how the health of the container is checked.
+	HealthCheck *models.ServiceHealthCheck `json:"health_check,omitempty"`
+	// Resources optionally defines the resource limits of the container.
+	Resources *models.ServiceResources `json:"resources,omitempty"`
+	// RestartPolicy optionally defines when the container is restarted.
+	RestartPolicy *models.ServiceRestartPolicy `json:"restart_policy,omitempty"`
 }
 
 // CreateServiceResponse is the response payload after successfully creating a service.
@@ -29,15 +41,197 @@ type CreateServiceResponse struct {
 	models.Service
 }
 
+// UpdateServiceRequest is the request payload for partially updating an
+// existing service. Only the provided fields are changed.
+type UpdateServiceRequest struct {
+	// Name is the new name for the service.
+	Name *string `json:"name,omitempty"`
+	// Image is the new Docker image and tag to deploy.
+	Image *string `json:"image,omitempty"`
+	// Environment replaces the environment variables passed to the container.
+	Environment *map[string]string `json:"environment,omitempty"`
+	// Mounts replaces the volume and bind mounts for the container.
+	Mounts *[]models.ServiceMount `json:"mounts,omitempty"`
+	// Dependencies replaces the services that must be running before this one starts.
+	Dependencies *[]models.ServiceDependency `json:"dependencies,omitempty"`
+	// NetworkAccess indicates whether the service should be exposed externally.
+	NetworkAccess *bool `json:"network_access,omitempty"`
+	// Ports replaces the container ports published on the host.
+	Ports *[]models.ServicePort `json:"ports,omitempty"`
+	// HealthCheck replaces the health check; an empty command disables it.
+	HealthCheck *models.ServiceHealthCheck `json:"health_check,omitempty"`
+	// Resources replaces the resource limits; zero values remove a limit.
+	Resources *models.ServiceResources `json:"resources,omitempty"`
+	// RestartPolicy replaces the restart policy of the container.
+	RestartPolicy *models.ServiceRestartPolicy `json:"restart_policy,omitempty"`
+	// ClearHealthCheck removes the health check, so the one of the image is
+	// used. It can't be combined with `HealthCheck`.
+	ClearHealthCheck bool `json:"clear_health_check,omitempty"`
+	// ClearResources removes all resource limits. It can't be combined with
+	// `Resources`.
+	ClearResources bool `json:"clear_resources,omitempty"`
+	// ClearRestartPolicy removes the restart policy, so the container is
+	// never restarted. It can't be combined with `RestartPolicy`.
+	ClearRestartPolicy bool `json:"clear_restart_policy,omitempty"`
+}
+
+// ServiceStateCrashLooping is reported as the state of a service whose
+// container crashed repeatedly within a short period.
+const ServiceStateCrashLooping container.ContainerState = "crash_looping"
+
 // ServiceStatusResponse provides runtime status information about a deployed
 // service at the specific point of time.
 type ServiceStatusResponse struct {
-	// State is the current container state.
+	// State is the current container state, or `ServiceStateCrashLooping`
+	// if the container keeps crashing.
 	State container.ContainerState `json:"state"`
+	// Paused indicates whether the container processes are currently paused.
+	Paused bool `json:"paused"`
 	// StartedAt is the timestamp when the container started (if running).
 	StartedAt string `json:"started_at"`
 	// FinishedAt is the timestamp when the container stopped (if stopped).
 	FinishedAt string `json:"finished_at"`
 	// ExitCode is the exit code if the container has stopped.
 	ExitCode int `json:"exit_code"`
+	// Ports lists the ports actually bound on the host.
+	Ports []PortBinding `json:"ports"`
+}
+
+// PortBinding describes a container port bound to a host port.
+type PortBinding struct {
+	// ContainerPort is the port inside the container.
+	ContainerPort int `json:"container_port"`
+	// Protocol is the transport protocol of the port.
+	Protocol string `json:"protocol"`
+	// HostIP is the host address the port is bound to.
+	HostIP string `json:"host_ip"`
+	// HostPort is the port on the host.
+	HostPort int `json:"host_port"`
+}
+
+// BatchServiceStatusRequest is the request payload for retrieving the status
+// of multiple services at once.
+type BatchServiceStatusRequest struct {
+	// ServiceIDs lists the services whose status should be retrieved.
+	ServiceIDs []uuid.UUID `json:"service_ids"`
+	// ProjectID optionally includes every service of the given project.
+	ProjectID *uuid.UUID `json:"project_id,omitempty"`
+}
+
+// ServiceStatusResult is the status of a single service within a batch
+// status report. Exactly one of `Status` and `Error` is set.
+type ServiceStatusResult struct {
+	// Status is the runtime status of the service container.
+	Status *ServiceStatusResponse `json:"status,omitempty"`
+	// Error describes why the status could not be retrieved.
+	Error string `json:"error,omitempty"`
+}
+
+// ContainerNetwork describes the attachment of a container to a single network.
+type ContainerNetwork struct {
+	// IPAddress is the IPv4 address of the container in the network.
+	IPAddress string `json:"ip_address,omitempty"`
+	// IPv6Address is the global IPv6 address of the container in the network.
+	IPv6Address string `json:"ipv6_address,omitempty"`
+	// Gateway is the IPv4 gateway of the network.
+	Gateway string `json:"gateway,omitempty"`
+	// MacAddress is the MAC address of the container endpoint.
+	MacAddress string `json:"mac_address,omitempty"`
+	// Aliases are the DNS names of the container within the network.
+	Aliases []string `json:"aliases"`
+}
+
+// ContainerMount describes a mount as it was actually applied to a container.
+type ContainerMount struct {
+	// Type is the type of the mount (e.g. `volume` or `bind`).
+	Type mount.Type `json:"type"`
+	// Name is the volume name (for volume mounts).
+	Name string `json:"name,omitempty"`
+	// Source is the location of the mount on the host.
+	Source string `json:"source"`
+	// Destination is the path inside the container.
+	Destination string `json:"destination"`
+	// ReadOnly indicates whether the mount is read-only.
+	ReadOnly bool `json:"read_only"`
+}
+
+// ServiceContainerResponse is a curated view of the container backing a
+// service, as reported by Docker.
+type ServiceContainerResponse struct {
+	// ID is the Docker container ID.
+	ID string `json:"id"`
+	// Name is the Docker container name.
+	Name string `json:"name"`
+	// Image is the image reference the container was created from.
+	Image string `json:"image"`
+	// ImageDigest is the digest of the image the container runs.
+	ImageDigest string `json:"image_digest"`
+	// CreatedAt is the timestamp when the container was created.
+	CreatedAt string `json:"created_at"`
+	// RestartCount is the number of times Docker restarted the container.
+	RestartCount int `json:"restart_count"`
+	// OOMKilled indicates whether the container was killed for running out of memory.
+	OOMKilled bool `json:"oom_killed"`
+	// Entrypoint is the entrypoint of the container.
+	Entrypoint []string `json:"entrypoint"`
+	// Command is the command (arguments to the entrypoint) of the container.
+	Command []string `json:"command"`
+	// Labels are the labels attached to the container.
+	Labels map[string]string `json:"labels"`
+	// ExposedPorts lists the exposed ports in the `port/protocol` form.
+	ExposedPorts []string `json:"exposed_ports"`
+	// Networks maps network names to the container attachment in them.
+	Networks map[string]ContainerNetwork `json:"networks"`
+	// Mounts lists the mounts as actually applied to the container.
+	Mounts []ContainerMount `json:"mounts"`
+}
+
+// HealthProbeResult is the result of a single health check probe.
+type HealthProbeResult struct {
+	// Start is the time the probe started.
+	Start time.Time `json:"start"`
+	// End is the time the probe finished.
+	End time.Time `json:"end"`
+	// ExitCode is the exit code of the probe (0 means healthy).
+	ExitCode int `json:"exit_code"`
+	// Output is the output of the probe.
+	Output string `json:"output"`
+}
+
+// ServiceHealthResponse provides the current health of a service container
+// along with its most recent probe results.
+type ServiceHealthResponse struct {
+	// Status is the current health status of the container.
+	Status container.HealthStatus `json:"status"`
+	// FailingStreak is the number of consecutive failed probes.
+	FailingStreak int `json:"failing_streak"`
+	// Probes contains the most recent probe results (oldest first).
+	Probes []HealthProbeResult `json:"probes"`
+}
+
+// ServiceLogsRequest describes which logs of a service container to read.
+type ServiceLogsRequest struct {
+	// Tail is the number of lines to return from the end of the logs, or
+	// `all`.
+	Tail string
+	// Since only returns logs after this time (RFC 3339, Unix timestamp or a
+	// duration relative to now, e.g. `10m`).
+	Since string
+	// Until only returns logs before this time, in the same formats as
+	// `Since`.
+	Until string
+	// Follow keeps streaming new logs until the client disconnects.
+	Follow bool
+	// Timestamps includes the timestamp of every log entry.
+	Timestamps bool
+	// Stream only returns the entries of this output stream. Entries of both
+	// streams are returned when it is empty.
+	Stream pkg.LogStream
+}
+
+// ServiceLogsResponse contains the logs of a service container when they
+// are not followed.
+type ServiceLogsResponse struct {
+	// Entries contains the log entries, oldest first.
+	Entries []pkg.LogEntry `json:"entries"`
 }
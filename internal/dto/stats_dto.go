@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Pelfox/gidock/internal/models"
+)
+
+// ServiceStatsResponse is a single resource usage sample of a service
+// container.
+type ServiceStatsResponse struct {
+	// Timestamp is the time the sample was collected by Docker.
+	Timestamp time.Time `json:"timestamp"`
+	// CPUPercent is the CPU usage since the previous sample, where 100% is one
+	// fully used CPU.
+	CPUPercent float64 `json:"cpu_percent"`
+	// MemoryUsage is the memory used by the container in bytes, excluding the
+	// page cache.
+	MemoryUsage uint64 `json:"memory_usage"`
+	// MemoryLimit is the memory available to the container in bytes.
+	MemoryLimit uint64 `json:"memory_limit"`
+	// MemoryPercent is the memory usage relative to the limit.
+	MemoryPercent float64 `json:"memory_percent"`
+	// NetworkRx is the number of bytes received on all interfaces.
+	NetworkRx uint64 `json:"network_rx"`
+	// NetworkTx is the number of bytes sent on all interfaces.
+	NetworkTx uint64 `json:"network_tx"`
+	// BlockRead is the number of bytes read from block devices.
+	BlockRead uint64 `json:"block_read"`
+	// BlockWrite is the number of bytes written to block devices.
+	BlockWrite uint64 `json:"block_write"`
+	// Pids is the number of processes in the container.
+	Pids uint64 `json:"pids"`
+}
+
+// StatsHistoryRequest describes the time window of a stats history query.
+type StatsHistoryRequest struct {
+	// From is the start of the window. It defaults to one hour before `To`.
+	From *time.Time
+	// To is the end of the window. It defaults to now.
+	To *time.Time
+	// Step is the width of a single bucket of the series. It defaults to a
+	// step yielding about 60 buckets.
+	Step time.Duration
+}
+
+// StatsHistoryResponse is a downsampled series of recorded stats samples.
+type StatsHistoryResponse struct {
+	// From is the start of the window.
+	From time.Time `json:"from"`
+	// To is the end of the window.
+	To time.Time `json:"to"`
+	// Step is the width of a single bucket in seconds.
+	Step int `json:"step"`
+	// Samples contains the aggregated samples of every non-empty bucket,
+	// ordered by time.
+	Samples []models.StatsSample `json:"samples"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+import "github.com/google/uuid"
+
+// CreateVolumeRequest is the request payload for creating a new project volume.
+type CreateVolumeRequest struct {
+	// Name is the Docker name of the volume, referenced by `ServiceMount.Source`.
+	Name string `json:"name"`
+	// Driver is the volume driver to use (defaults to `local`).
+	Driver string `json:"driver"`
+}
+
+// VolumeUser identifies a service that mounts a volume.
+type VolumeUser struct {
+	// ServiceID is the unique identifier of the service.
+	ServiceID uuid.UUID `json:"service_id"`
+	// Name is the name of the service.
+	Name string `json:"name"`
+}
+
+// VolumeResponse describes a project volume.
+type VolumeResponse struct {
+	// Name is the Docker name of the volume.
+	Name string `json:"name"`
+	// Driver is the volume driver used by the volume.
+	Driver string `json:"driver"`
+	// CreatedAt is the timestamp when the volume was created.
+	CreatedAt string `json:"created_at"`
+	// Size is the disk space used by the volume in bytes, or -1 if unknown.
+	Size int64 `json:"size"`
+	// UsedBy lists the project services mounting the volume.
+	UsedBy []VolumeUser `json:"used_by"`
+}
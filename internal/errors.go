@@ -11,4 +11,43 @@ var (
 	ErrNoContainer = errors.New("service has no associated container")
 	// ErrNoFields indicates that no fields were provided for an update operation.
 	ErrNoFields = errors.New("no fields to update")
+	// ErrDependencyCycle indicates that service dependencies form a cycle.
+	ErrDependencyCycle = errors.New("service dependencies form a cycle")
+	// ErrInvalidDependency indicates that a service dependency references an
+	// unknown service or uses an unknown condition.
+	ErrInvalidDependency = errors.New("invalid service dependency")
+	// ErrInvalidServiceSpec indicates that the container settings of a service are invalid.
+	ErrInvalidServiceSpec = errors.New("invalid service specification")
+	// ErrServiceInUse indicates that the service is a dependency of other services.
+	ErrServiceInUse = errors.New("service is a dependency of other services")
+	// ErrInvalidVolumeName indicates that the provided volume name is not valid.
+	ErrInvalidVolumeName = errors.New("invalid volume name")
+	// ErrVolumeExists indicates that a volume with the same name already exists.
+	ErrVolumeExists = errors.New("volume already exists")
+	// ErrVolumeInUse indicates that the volume is still mounted by services.
+	ErrVolumeInUse = errors.New("volume is in use by services")
+	// ErrInvalidArchive indicates that a volume archive isn't a valid
+	// gzip-compressed tar archive.
+	ErrInvalidArchive = errors.New("invalid volume archive")
+	// ErrHelperFailed indicates that a short-lived helper container failed.
+	ErrHelperFailed = errors.New("helper container failed")
+	// ErrDependencyNotReady indicates that a dependency did not satisfy its
+	// condition in time.
+	ErrDependencyNotReady = errors.New("dependency did not become ready")
+	// ErrNoHealthCheck indicates that the container has no health check configured.
+	ErrNoHealthCheck = errors.New("container has no health check configured")
+	// ErrContainerExited indicates that the container exited unexpectedly.
+	ErrContainerExited = errors.New("container exited unexpectedly")
+	// ErrPortConflict indicates that a host port is already published by another service.
+	ErrPortConflict = errors.New("host port is already in use")
+	// ErrNoFreePort indicates that every port of the host port allocation range is taken.
+	ErrNoFreePort = errors.New("no free host port left in the allocation range")
+	// ErrInvalidTimeWindow indicates that the requested time window is empty or too large.
+	ErrInvalidTimeWindow = errors.New("invalid time window")
+	// ErrCommandTimeout indicates that a command didn't exit within its timeout.
+	ErrCommandTimeout = errors.New("command timed out")
+	// ErrInvalidCommand indicates that the command to run is invalid.
+	ErrInvalidCommand = errors.New("invalid command")
+	// ErrServiceNotInProject indicates that the service doesn't belong to the project.
+	ErrServiceNotInProject = errors.New("service does not belong to the project")
 )
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PortAllocation is a host port assigned by gidock to a published service
+// port that doesn't specify a host port itself. Allocations are kept across
+// container recreations, so the service stays reachable on the same port.
+type PortAllocation struct {
+	// HostPort is the allocated port on the host.
+	HostPort int `json:"host_port" db:"host_port"`
+	// Protocol is the transport protocol of the port.
+	Protocol string `json:"protocol" db:"protocol"`
+	// ServiceID is the ID of the service the port is allocated to.
+	ServiceID uuid.UUID `json:"service_id" db:"service_id"`
+	// ContainerPort is the container port published on the allocated port.
+	ContainerPort int `json:"container_port" db:"container_port"`
+	// CreatedAt is the timestamp when the port was allocated.
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -6,16 +6,139 @@ import (
 	"github.com/google/uuid"
 )
 
-// ServiceMount defines a single volume or bind mount for a service container.
-type ServiceMount struct {
-	// TODO: specify whether it is a volume or a host mount
+// MountType represents the kind of service mount.
+type MountType string
+
+const (
+	// MountTypeVolume mounts a Docker named (or anonymous) volume.
+	MountTypeVolume MountType = "volume"
+	// MountTypeBind mounts a host path into the container.
+	MountTypeBind MountType = "bind"
+	// MountTypeTmpfs mounts an in-memory filesystem.
+	MountTypeTmpfs MountType = "tmpfs"
+)
 
-	// Source is the host path, volume name, or named volume source.
+// ServiceMount defines a single volume, bind or tmpfs mount for a service
+// container.
+type ServiceMount struct {
+	// Type is the kind of the mount. An empty type is treated as a volume.
+	Type MountType `json:"type"`
+	// Source is the volume name for volume mounts or the host path for bind
+	// mounts. It must be empty for tmpfs mounts.
 	Source string `json:"source"`
 	// Target is the path inside the container where the source is mounted.
 	Target string `json:"target"`
 	// ReadOnly indicates whether the mount should be read-only inside the container.
 	ReadOnly bool `json:"read_only"`
+	// BindPropagation is the propagation mode of a bind mount (e.g. `rprivate`).
+	BindPropagation string `json:"bind_propagation,omitempty"`
+	// TmpfsSize is the size of a tmpfs mount in bytes (zero means unlimited).
+	TmpfsSize int64 `json:"tmpfs_size,omitempty"`
+	// TmpfsMode is the file mode of a tmpfs mount in octal notation (e.g. `1777`).
+	TmpfsMode string `json:"tmpfs_mode,omitempty"`
+}
+
+// ServicePort defines a container port published on the host.
+type ServicePort struct {
+	// ContainerPort is the port inside the container.
+	ContainerPort int `json:"container_port"`
+	// Protocol is the transport protocol (`tcp`, `udp` or `sctp`). An empty
+	// protocol is treated as `tcp`.
+	Protocol string `json:"protocol"`
+	// HostPort is the port on the host. Zero allocates a port from the
+	// configured range, which is kept across container recreations.
+	HostPort int `json:"host_port,omitempty"`
+	// HostIP is the host address to bind to. Empty binds to all addresses.
+	HostIP string `json:"host_ip,omitempty"`
+}
+
+// PortProtocol returns the protocol of the port, defaulting to `tcp`.
+func (p ServicePort) PortProtocol() string {
+	if p.Protocol == "" {
+		return "tcp"
+	}
+	return p.Protocol
+}
+
+// ServiceHealthCheck defines how Docker checks that a service container is
+// healthy. All durations are in seconds; zero values inherit the defaults of
+// the image (or Docker).
+type ServiceHealthCheck struct {
+	// Command is the probe to run inside the container. A single element is
+	// run with the container's default shell, multiple elements are executed
+	// directly. An empty command disables the health check.
+	Command []string `json:"command"`
+	// Interval is the time to wait between two probes.
+	Interval int `json:"interval"`
+	// Timeout is the time after which a single probe is considered hung.
+	Timeout int `json:"timeout"`
+	// Retries is the number of consecutive failures needed to consider the
+	// container unhealthy.
+	Retries int `json:"retries"`
+	// StartPeriod is the time the container is given to initialize before
+	// failing probes start counting towards `Retries`.
+	StartPeriod int `json:"start_period"`
+}
+
+// ServiceResources defines the resource limits of a service container. Zero
+// values leave the corresponding resource unlimited (or at Docker's default).
+type ServiceResources struct {
+	// MemoryLimit is the hard memory limit in bytes.
+	MemoryLimit int64 `json:"memory_limit,omitempty"`
+	// MemoryReservation is the soft memory limit in bytes, enforced when the
+	// host runs low on memory.
+	MemoryReservation int64 `json:"memory_reservation,omitempty"`
+	// NanoCPUs is the CPU limit in units of 10^-9 CPUs (e.g. 1500000000 for
+	// 1.5 CPUs). It can't be combined with `CPUQuota`.
+	NanoCPUs int64 `json:"nano_cpus,omitempty"`
+	// CPUQuota is the CPU time in microseconds the container may use per
+	// `CPUPeriod`.
+	CPUQuota int64 `json:"cpu_quota,omitempty"`
+	// CPUPeriod is the length of a CPU scheduling period in microseconds.
+	// Zero uses Docker's default of 100000.
+	CPUPeriod int64 `json:"cpu_period,omitempty"`
+	// PidsLimit is the maximum number of processes in the container.
+	PidsLimit int64 `json:"pids_limit,omitempty"`
+	// ShmSize is the size of `/dev/shm` in bytes.
+	ShmSize int64 `json:"shm_size,omitempty"`
+}
+
+// RestartPolicyName is the name of a container restart policy.
+type RestartPolicyName string
+
+const (
+	// RestartPolicyNo never restarts the container.
+	RestartPolicyNo RestartPolicyName = "no"
+	// RestartPolicyOnFailure restarts the container when it exits with a
+	// non-zero code, up to `MaxRetries` times (unlimited if zero).
+	RestartPolicyOnFailure RestartPolicyName = "on-failure"
+	// RestartPolicyAlways always restarts the container, also after the
+	// Docker daemon restarts.
+	RestartPolicyAlways RestartPolicyName = "always"
+	// RestartPolicyUnlessStopped always restarts the container, unless it was
+	// stopped before the Docker daemon restarted.
+	RestartPolicyUnlessStopped RestartPolicyName = "unless-stopped"
+)
+
+// IsValid reports whether the name is one of the defined
+// `RestartPolicyName` constants.
+func (n RestartPolicyName) IsValid() bool {
+	switch n {
+	case RestartPolicyNo, RestartPolicyOnFailure, RestartPolicyAlways, RestartPolicyUnlessStopped:
+		return true
+	default:
+		return false
+	}
+}
+
+// ServiceRestartPolicy defines when Docker restarts the service container
+// after it exits.
+type ServiceRestartPolicy struct {
+	// Name is the restart policy.
+	Name RestartPolicyName `json:"name"`
+	// MaxRetries is the maximum number of restarts for the `on-failure`
+	// policy. Zero means unlimited.
+	MaxRetries int `json:"max_retries,omitempty"`
 }
 
 // ServiceCondition represents the condition a dependency must satisfy.
@@ -31,6 +154,17 @@ const (
 	ServiceConditionReady ServiceCondition = "ready"
 )
 
+// IsValid reports whether the condition is one of the defined
+// `ServiceCondition` constants.
+func (c ServiceCondition) IsValid() bool {
+	switch c {
+	case ServiceConditionHealthy, ServiceConditionReady:
+		return true
+	default:
+		return false
+	}
+}
+
 // ServiceDependency expresses that one service depends on another service
 // being in a specific state before it can start.
 type ServiceDependency struct {
@@ -58,12 +192,28 @@ type Service struct {
 	// Dependencies lists other services that must be running before this
 	// service can start.
 	Dependencies []ServiceDependency `json:"dependencies" db:"dependencies"`
-	// NetworkAccess determines whether the service should be exposed to the
-	// external network.
+	// NetworkAccess determines whether the service can reach the external
+	// network. Every service joins the internal project network, where it is
+	// reachable by other services under its DNS alias; services with network
+	// access additionally join the external project bridge network.
 	NetworkAccess bool `json:"network_access" db:"network_access"`
+	// Ports lists the container ports published on the host.
+	Ports []ServicePort `json:"ports" db:"ports"`
+	// HealthCheck optionally defines how the health of the service container
+	// is checked.
+	HealthCheck *ServiceHealthCheck `json:"health_check" db:"health_check"`
+	// Resources optionally defines the resource limits of the container.
+	Resources *ServiceResources `json:"resources" db:"resources"`
+	// RestartPolicy optionally defines when the container is restarted after
+	// it exits. Containers aren't restarted without one.
+	RestartPolicy *ServiceRestartPolicy `json:"restart_policy" db:"restart_policy"`
 	// ContainerID is the runtime identifier of the container (set after
 	// deployment).
 	ContainerID *string `json:"container_id" db:"container_id"`
+	// NeedsRedeploy indicates that the service definition was changed after
+	// its container was created, so the container must be recreated for the
+	// changes to take effect.
+	NeedsRedeploy bool `json:"needs_redeploy" db:"needs_redeploy"`
 	// CreatedAt is the timestamp when the service was created.
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	// UpdatedAt is the timestamp of the last update.
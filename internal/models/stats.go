@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatsSample is a recorded resource usage sample of a service container.
+// Downsampled series reuse it, with every value aggregated over the bucket
+// starting at `SampledAt`.
+type StatsSample struct {
+	// ServiceID is the ID of the sampled service. It is `uuid.Nil` for
+	// samples aggregated over a whole project.
+	ServiceID uuid.UUID `json:"service_id" db:"service_id"`
+	// SampledAt is the time the sample was collected.
+	SampledAt time.Time `json:"sampled_at" db:"sampled_at"`
+	// CPUPercent is the CPU usage, where 100% is one fully used CPU.
+	CPUPercent float64 `json:"cpu_percent" db:"cpu_percent"`
+	// MemoryUsage is the memory used by the container in bytes.
+	MemoryUsage int64 `json:"memory_usage" db:"memory_usage"`
+	// MemoryLimit is the memory available to the container in bytes.
+	MemoryLimit int64 `json:"memory_limit" db:"memory_limit"`
+	// NetworkRx is the number of bytes received since the previous sample.
+	NetworkRx int64 `json:"network_rx" db:"network_rx"`
+	// NetworkTx is the number of bytes sent since the previous sample.
+	NetworkTx int64 `json:"network_tx" db:"network_tx"`
+}
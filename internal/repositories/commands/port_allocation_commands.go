@@ -0,0 +1,43 @@
+package commands
+
+import "github.com/google/uuid"
+
+// AllocatePortCommand represents the data required to allocate a host port
+// for a published service port.
+type AllocatePortCommand struct {
+	// ServiceID is the unique identifier of the service publishing the port.
+	ServiceID uuid.UUID
+	// ContainerPort is the container port to allocate a host port for.
+	ContainerPort int
+	// Protocol is the transport protocol of the port.
+	Protocol string
+	// RangeStart is the first host port that may be allocated.
+	RangeStart int
+	// RangeEnd is the last host port that may be allocated.
+	RangeEnd int
+}
+
+// ListServicePortAllocationsCommand represents the data required to list the
+// host ports allocated to a service.
+type ListServicePortAllocationsCommand struct {
+	// ServiceID is the unique identifier of the service.
+	ServiceID uuid.UUID
+}
+
+// ReleasePortAllocationCommand represents the data required to release a
+// single host port allocated to a service.
+type ReleasePortAllocationCommand struct {
+	// ServiceID is the unique identifier of the service.
+	ServiceID uuid.UUID
+	// HostPort is the allocated host port to release.
+	HostPort int
+	// Protocol is the transport protocol of the port.
+	Protocol string
+}
+
+// ReleaseServicePortAllocationsCommand represents the data required to
+// release every host port allocated to a service.
+type ReleaseServicePortAllocationsCommand struct {
+	// ServiceID is the unique identifier of the service.
+	ServiceID uuid.UUID
+}
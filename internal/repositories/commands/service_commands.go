@@ -21,6 +21,14 @@ type CreateServiceCommand struct {
 	Dependencies []models.ServiceDependency
 	// NetworkAccess indicates whether the service has network access.
 	NetworkAccess bool
+	// Ports contains the published ports of the service.
+	Ports []models.ServicePort
+	// HealthCheck contains the health check of the service.
+	HealthCheck *models.ServiceHealthCheck
+	// Resources contains the resource limits of the service.
+	Resources *models.ServiceResources
+	// RestartPolicy contains the restart policy of the service.
+	RestartPolicy *models.ServiceRestartPolicy
 }
 
 // GetServiceCommand represents the data required to retrieve a service.
@@ -29,12 +37,47 @@ type GetServiceCommand struct {
 	ID uuid.UUID
 }
 
+// ListProjectServicesCommand represents the data required to list all
+// services of a project.
+type ListProjectServicesCommand struct {
+	// ProjectID is the unique identifier of the project whose services are listed.
+	ProjectID uuid.UUID
+}
+
 // UpdateServiceCommand represents a partial update request for a service.
 type UpdateServiceCommand struct {
 	// ID is the unique identifier of the service to be updated.
 	ID uuid.UUID
+	// Name is the new human-readable name for the service.
+	Name *string
+	// Image is the new container image used for the service.
+	Image *string
+	// Environment replaces the environment variables of the service.
+	Environment *map[string]string
+	// Mounts replaces the volume mounts of the service.
+	Mounts *[]models.ServiceMount
+	// Dependencies replaces the service dependencies.
+	Dependencies *[]models.ServiceDependency
+	// NetworkAccess indicates whether the service has network access.
+	NetworkAccess *bool
+	// Ports replaces the published ports of the service.
+	Ports *[]models.ServicePort
+	// HealthCheck replaces the health check of the service.
+	HealthCheck *models.ServiceHealthCheck
+	// Resources replaces the resource limits of the service.
+	Resources *models.ServiceResources
+	// RestartPolicy replaces the restart policy of the service.
+	RestartPolicy *models.ServiceRestartPolicy
+	// ClearHealthCheck removes the health check of the service.
+	ClearHealthCheck bool
+	// ClearResources removes the resource limits of the service.
+	ClearResources bool
+	// ClearRestartPolicy removes the restart policy of the service.
+	ClearRestartPolicy bool
 	// ContainerID is the new container ID for the service.
 	ContainerID *string
+	// NeedsRedeploy indicates whether the service container must be recreated.
+	NeedsRedeploy *bool
 }
 
 // DeleteServiceCommand represents the data required to delete a service.
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"time"
+
+	"github.com/Pelfox/gidock/internal/models"
+	"github.com/google/uuid"
+)
+
+// RecordStatsCommand represents the data required to record stats samples.
+type RecordStatsCommand struct {
+	// Samples contains the samples to record.
+	Samples []models.StatsSample
+}
+
+// PruneStatsCommand represents the data required to remove old stats samples.
+type PruneStatsCommand struct {
+	// Before is the time before which all samples are removed.
+	Before time.Time
+}
+
+// GetServiceStatsCommand represents the data required to retrieve the
+// downsampled stats of a service.
+type GetServiceStatsCommand struct {
+	// ServiceID is the unique identifier of the service.
+	ServiceID uuid.UUID
+	// From is the (inclusive) start of the time window.
+	From time.Time
+	// To is the (exclusive) end of the time window.
+	To time.Time
+	// Step is the width of a single bucket of the series.
+	Step time.Duration
+}
+
+// GetProjectStatsCommand represents the data required to retrieve the
+// downsampled stats of all services of a project, summed up.
+type GetProjectStatsCommand struct {
+	// ProjectID is the unique identifier of the project.
+	ProjectID uuid.UUID
+	// From is the (inclusive) start of the time window.
+	From time.Time
+	// To is the (exclusive) end of the time window.
+	To time.Time
+	// Step is the width of a single bucket of the series.
+	Step time.Duration
+}
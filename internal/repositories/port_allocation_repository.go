@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	s "github.com/Masterminds/squirrel"
+	"github.com/Pelfox/gidock/internal"
+	"github.com/Pelfox/gidock/internal/models"
+	"github.com/Pelfox/gidock/internal/repositories/commands"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// allocatePortAttempts is the number of times an allocation is retried when a
+// concurrent allocation took the selected port first.
+const allocatePortAttempts = 5
+
+// allocatePortQuery inserts an allocation for the lowest port of the range
+// that is neither allocated nor explicitly published by any service.
+const allocatePortQuery = `
+INSERT INTO port_allocations (host_port, protocol, service_id, container_port)
+SELECT candidate, $1, $2, $3
+FROM generate_series($4::integer, $5::integer) AS candidate
+WHERE NOT EXISTS (
+    SELECT 1 FROM port_allocations
+    WHERE host_port = candidate AND protocol = $1
+)
+AND NOT EXISTS (
+    SELECT 1 FROM services, jsonb_array_elements(services.ports) AS port
+    WHERE (port->>'host_port')::integer = candidate
+      AND COALESCE(NULLIF(port->>'protocol', ''), 'tcp') = $1
+)
+ORDER BY candidate
+LIMIT 1
+RETURNING *`
+
+// PortAllocationRepository provides data access methods for the
+// `port_allocations` table.
+type PortAllocationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPortAllocationRepository creates a new PortAllocationRepository instance
+// from the given `*pgxpool.Pool`.
+func NewPortAllocationRepository(pool *pgxpool.Pool) *PortAllocationRepository {
+	return &PortAllocationRepository{pool: pool}
+}
+
+// Allocate allocates the lowest free host port of the range for the service
+// port with given command and returns the allocation. It returns
+// `internal.ErrNoFreePort` when every port of the range is taken.
+func (r *PortAllocationRepository) Allocate(
+	ctx context.Context,
+	command commands.AllocatePortCommand,
+) (*models.PortAllocation, error) {
+	for range allocatePortAttempts {
+		rows, err := r.pool.Query(
+			ctx,
+			allocatePortQuery,
+			command.Protocol,
+			command.ServiceID,
+			command.ContainerPort,
+			command.RangeStart,
+			command.RangeEnd,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Allocate: failed to execute query: %w", err)
+		}
+
+		allocation, err := pgx.CollectOneRow[models.PortAllocation](rows, pgx.RowToStructByName[models.PortAllocation])
+		rows.Close()
+		if err == nil {
+			return &allocation, nil
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, internal.ErrNoFreePort
+		}
+
+		// the selected port was allocated concurrently, so try the next one
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			continue
+		}
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return nil, internal.ErrRelationNotFound
+		}
+		return nil, fmt.Errorf("Allocate: failed to map: %w", err)
+	}
+	return nil, fmt.Errorf("Allocate: gave up after %d concurrent allocations", allocatePortAttempts)
+}
+
+// ListAll retrieves all port allocations from the database.
+func (r *PortAllocationRepository) ListAll(ctx context.Context) ([]models.PortAllocation, error) {
+	query, args, err := sq.Select("*").From("port_allocations").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ListAll: failed to build query: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ListAll: failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	allocations, err := pgx.CollectRows[models.PortAllocation](rows, pgx.RowToStructByName[models.PortAllocation])
+	if err != nil {
+		return nil, fmt.Errorf("ListAll: failed to map: %w", err)
+	}
+
+	return allocations, nil
+}
+
+// ListByService retrieves all port allocations of the service with given
+// command.
+func (r *PortAllocationRepository) ListByService(
+	ctx context.Context,
+	command commands.ListServicePortAllocationsCommand,
+) ([]models.PortAllocation, error) {
+	query, args, err := sq.Select("*").
+		From("port_allocations").
+		Where(s.Eq{"service_id": command.ServiceID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ListByService: failed to build query: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ListByService: failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	allocations, err := pgx.CollectRows[models.PortAllocation](rows, pgx.RowToStructByName[models.PortAllocation])
+	if err != nil {
+		return nil, fmt.Errorf("ListByService: failed to map: %w", err)
+	}
+
+	return allocations, nil
+}
+
+// Release removes a single port allocation of a service with given command.
+func (r *PortAllocationRepository) Release(
+	ctx context.Context,
+	command commands.ReleasePortAllocationCommand,
+) error {
+	query, args, err := sq.Delete("port_allocations").
+		Where(s.Eq{
+			"service_id": command.ServiceID,
+			"host_port":  command.HostPort,
+			"protocol":   command.Protocol,
+		}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("Release: failed to build query: %w", err)
+	}
+
+	if _, err := r.pool.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("Release: failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseByService removes every port allocation of a service with given
+// command.
+func (r *PortAllocationRepository) ReleaseByService(
+	ctx context.Context,
+	command commands.ReleaseServicePortAllocationsCommand,
+) error {
+	query, args, err := sq.Delete("port_allocations").
+		Where(s.Eq{"service_id": command.ServiceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ReleaseByService: failed to build query: %w", err)
+	}
+
+	if _, err := r.pool.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("ReleaseByService: failed to execute query: %w", err)
+	}
+
+	return nil
+}
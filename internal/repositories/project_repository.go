@@ -114,19 +114,19 @@ func (r *ProjectRepository) Update(
 	ctx context.Context,
 	command commands.UpdateProjectCommand,
 ) (*models.Project, error) {
-	queryBuilder := sq.Update("projects")
-
 	// updating all selected (non-nil) fields
+	fields := make(map[string]any)
 	if command.Name != nil {
-		queryBuilder = queryBuilder.Set("name", *command.Name)
+		fields["name"] = *command.Name
 	}
 
 	// if update fields are empty, return an error
-	if queryBuilder == sq.Update("projects") {
+	if len(fields) == 0 {
 		return nil, internal.ErrNoFields
 	}
 
-	query, args, err := queryBuilder.Where(s.Eq{"id": command.ID}).
+	query, args, err := sq.Update("projects").SetMap(fields).
+		Where(s.Eq{"id": command.ID}).
 		Suffix("RETURNING *").
 		ToSql()
 	if err != nil {
@@ -30,7 +30,19 @@ func (r *ServiceRepository) Create(
 	command commands.CreateServiceCommand,
 ) (*models.Service, error) {
 	query, args, err := sq.Insert("services").
-		Columns("project_id", "name", "image", "environment", "mounts", "dependencies", "network_access").
+		Columns(
+			"project_id",
+			"name",
+			"image",
+			"environment",
+			"mounts",
+			"dependencies",
+			"network_access",
+			"ports",
+			"health_check",
+			"resources",
+			"restart_policy",
+		).
 		Values(
 			command.ProjectID,
 			command.Name,
@@ -39,6 +51,10 @@ func (r *ServiceRepository) Create(
 			command.Mounts,
 			command.Dependencies,
 			command.NetworkAccess,
+			command.Ports,
+			command.HealthCheck,
+			command.Resources,
+			command.RestartPolicy,
 		).
 		Suffix("RETURNING *").
 		ToSql()
@@ -100,19 +116,58 @@ func (r *ServiceRepository) Update(
 	ctx context.Context,
 	command commands.UpdateServiceCommand,
 ) (*models.Service, error) {
-	queryBuilder := sq.Update("services")
-
 	// updating all selected (non-nil) fields
+	fields := make(map[string]any)
+	if command.Name != nil {
+		fields["name"] = *command.Name
+	}
+	if command.Image != nil {
+		fields["image"] = *command.Image
+	}
+	if command.Environment != nil {
+		fields["environment"] = *command.Environment
+	}
+	if command.Mounts != nil {
+		fields["mounts"] = *command.Mounts
+	}
+	if command.Dependencies != nil {
+		fields["dependencies"] = *command.Dependencies
+	}
+	if command.NetworkAccess != nil {
+		fields["network_access"] = *command.NetworkAccess
+	}
+	if command.Ports != nil {
+		fields["ports"] = *command.Ports
+	}
+	if command.HealthCheck != nil {
+		fields["health_check"] = *command.HealthCheck
+	} else if command.ClearHealthCheck {
+		fields["health_check"] = nil
+	}
+	if command.Resources != nil {
+		fields["resources"] = *command.Resources
+	} else if command.ClearResources {
+		fields["resources"] = nil
+	}
+	if command.RestartPolicy != nil {
+		fields["restart_policy"] = *command.RestartPolicy
+	} else if command.ClearRestartPolicy {
+		fields["restart_policy"] = nil
+	}
 	if command.ContainerID != nil {
-		queryBuilder = queryBuilder.Set("container_id", *command.ContainerID)
+		fields["container_id"] = *command.ContainerID
+	}
+	if command.NeedsRedeploy != nil {
+		fields["needs_redeploy"] = *command.NeedsRedeploy
 	}
 
 	// if update fields are empty, return an error
-	if queryBuilder == sq.Update("services") {
+	if len(fields) == 0 {
 		return nil, internal.ErrNoFields
 	}
 
-	query, args, err := queryBuilder.Where(s.Eq{"id": command.ID}).
+	query, args, err := sq.Update("services").SetMap(fields).
+		Where(s.Eq{"id": command.ID}).
 		Suffix("RETURNING *").
 		ToSql()
 	if err != nil {
@@ -180,3 +235,31 @@ func (r *ServiceRepository) ListAll(ctx context.Context) ([]models.Service, erro
 
 	return services, nil
 }
+
+// ListByProject retrieves all services belonging to the project with given
+// command.
+func (r *ServiceRepository) ListByProject(
+	ctx context.Context,
+	command commands.ListProjectServicesCommand,
+) ([]models.Service, error) {
+	query, args, err := sq.Select("*").
+		From("services").
+		Where(s.Eq{"project_id": command.ProjectID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ListByProject: failed to build query: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ListByProject: failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	services, err := pgx.CollectRows[models.Service](rows, pgx.RowToStructByName[models.Service])
+	if err != nil {
+		return nil, fmt.Errorf("ListByProject: failed to map: %w", err)
+	}
+
+	return services, nil
+}
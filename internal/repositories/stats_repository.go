@@ -0,0 +1,207 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	s "github.com/Masterminds/squirrel"
+	"github.com/Pelfox/gidock/internal/models"
+	"github.com/Pelfox/gidock/internal/repositories/commands"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// recordStatsQuery inserts the samples given as column arrays. Samples of
+// services deleted in the meantime are skipped, since they would violate the
+// foreign key and fail the whole insert.
+const recordStatsQuery = `
+INSERT INTO service_stats (
+    service_id,
+    sampled_at,
+    cpu_percent,
+    memory_usage,
+    memory_limit,
+    network_rx,
+    network_tx
+)
+SELECT samples.*
+FROM unnest(
+    $1::UUID[],
+    $2::TIMESTAMPTZ[],
+    $3::DOUBLE PRECISION[],
+    $4::BIGINT[],
+    $5::BIGINT[],
+    $6::BIGINT[],
+    $7::BIGINT[]
+) AS samples (service_id, sampled_at, cpu_percent, memory_usage, memory_limit, network_rx, network_tx)
+WHERE EXISTS (SELECT 1 FROM services WHERE services.id = samples.service_id)
+ON CONFLICT DO NOTHING`
+
+// serviceStatsQuery aggregates the samples of a service into buckets. The
+// network traffic of a bucket is the sum of its samples.
+const serviceStatsQuery = `
+SELECT
+    service_id,
+    date_bin($4::DOUBLE PRECISION * INTERVAL '1 second', sampled_at, $2) AS sampled_at,
+    AVG(cpu_percent) AS cpu_percent,
+    AVG(memory_usage)::BIGINT AS memory_usage,
+    MAX(memory_limit) AS memory_limit,
+    SUM(network_rx)::BIGINT AS network_rx,
+    SUM(network_tx)::BIGINT AS network_tx
+FROM service_stats
+WHERE service_id = $1 AND sampled_at >= $2 AND sampled_at < $3
+GROUP BY 1, 2
+ORDER BY 2`
+
+// projectStatsQuery aggregates the samples of every service of a project
+// into buckets per service first, then sums the buckets up.
+const projectStatsQuery = `
+SELECT
+    '00000000-0000-0000-0000-000000000000'::UUID AS service_id,
+    sampled_at,
+    SUM(cpu_percent) AS cpu_percent,
+    SUM(memory_usage)::BIGINT AS memory_usage,
+    SUM(memory_limit)::BIGINT AS memory_limit,
+    SUM(network_rx)::BIGINT AS network_rx,
+    SUM(network_tx)::BIGINT AS network_tx
+FROM (
+    SELECT
+        stats.service_id,
+        date_bin($4::DOUBLE PRECISION * INTERVAL '1 second', stats.sampled_at, $2) AS sampled_at,
+        AVG(stats.cpu_percent) AS cpu_percent,
+        AVG(stats.memory_usage) AS memory_usage,
+        MAX(stats.memory_limit) AS memory_limit,
+        SUM(stats.network_rx) AS network_rx,
+        SUM(stats.network_tx) AS network_tx
+    FROM service_stats AS stats
+    JOIN services ON services.id = stats.service_id
+    WHERE services.project_id = $1 AND stats.sampled_at >= $2 AND stats.sampled_at < $3
+    GROUP BY 1, 2
+) AS per_service
+GROUP BY sampled_at
+ORDER BY sampled_at`
+
+// StatsRepository provides data access methods for the `service_stats` table.
+type StatsRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewStatsRepository creates a new StatsRepository instance from the given `*pgxpool.Pool`.
+func NewStatsRepository(pool *pgxpool.Pool) *StatsRepository {
+	return &StatsRepository{pool: pool}
+}
+
+// Record stores the samples with given command.
+func (r *StatsRepository) Record(ctx context.Context, command commands.RecordStatsCommand) error {
+	if len(command.Samples) == 0 {
+		return nil
+	}
+
+	count := len(command.Samples)
+	serviceIDs := make([]uuid.UUID, 0, count)
+	sampledAt := make([]time.Time, 0, count)
+	cpuPercent := make([]float64, 0, count)
+	memoryUsage := make([]int64, 0, count)
+	memoryLimit := make([]int64, 0, count)
+	networkRx := make([]int64, 0, count)
+	networkTx := make([]int64, 0, count)
+	for _, sample := range command.Samples {
+		serviceIDs = append(serviceIDs, sample.ServiceID)
+		sampledAt = append(sampledAt, sample.SampledAt)
+		cpuPercent = append(cpuPercent, sample.CPUPercent)
+		memoryUsage = append(memoryUsage, sample.MemoryUsage)
+		memoryLimit = append(memoryLimit, sample.MemoryLimit)
+		networkRx = append(networkRx, sample.NetworkRx)
+		networkTx = append(networkTx, sample.NetworkTx)
+	}
+
+	_, err := r.pool.Exec(
+		ctx,
+		recordStatsQuery,
+		serviceIDs,
+		sampledAt,
+		cpuPercent,
+		memoryUsage,
+		memoryLimit,
+		networkRx,
+		networkTx,
+	)
+	if err != nil {
+		return fmt.Errorf("Record: failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+// Prune removes the samples older than the time in given command.
+func (r *StatsRepository) Prune(ctx context.Context, command commands.PruneStatsCommand) (int64, error) {
+	query, args, err := sq.Delete("service_stats").
+		Where(s.Lt{"sampled_at": command.Before}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("Prune: failed to build query: %w", err)
+	}
+
+	cmdTag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("Prune: failed to execute query: %w", err)
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
+// GetServiceSeries retrieves the downsampled stats of a service with given
+// command.
+func (r *StatsRepository) GetServiceSeries(
+	ctx context.Context,
+	command commands.GetServiceStatsCommand,
+) ([]models.StatsSample, error) {
+	rows, err := r.pool.Query(
+		ctx,
+		serviceStatsQuery,
+		command.ServiceID,
+		command.From,
+		command.To,
+		command.Step.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("GetServiceSeries: failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	samples, err := pgx.CollectRows[models.StatsSample](rows, pgx.RowToStructByName[models.StatsSample])
+	if err != nil {
+		return nil, fmt.Errorf("GetServiceSeries: failed to map: %w", err)
+	}
+
+	return samples, nil
+}
+
+// GetProjectSeries retrieves the downsampled stats of all services of a
+// project, summed up per bucket, with given command.
+func (r *StatsRepository) GetProjectSeries(
+	ctx context.Context,
+	command commands.GetProjectStatsCommand,
+) ([]models.StatsSample, error) {
+	rows, err := r.pool.Query(
+		ctx,
+		projectStatsQuery,
+		command.ProjectID,
+		command.From,
+		command.To,
+		command.Step.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("GetProjectSeries: failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	samples, err := pgx.CollectRows[models.StatsSample](rows, pgx.RowToStructByName[models.StatsSample])
+	if err != nil {
+		return nil, fmt.Errorf("GetProjectSeries: failed to map: %w", err)
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Pelfox/gidock/internal"
+	"github.com/moby/moby/api/types/events"
+	"github.com/moby/moby/client"
+	"github.com/rs/zerolog/log"
+)
+
+// crashLoopReconnectDelay is the time to wait before subscribing to Docker
+// events again after the subscription failed.
+const crashLoopReconnectDelay = 5 * time.Second
+
+// containerCrashes tracks the recent crashes of a single container.
+type containerCrashes struct {
+	// stopping is set when the container was killed on purpose (e.g. by
+	// `docker stop`), so its next exit is not a crash.
+	stopping bool
+	crashes  []time.Time
+}
+
+// CrashLoopDetector follows the Docker events of service containers and
+// reports containers that crashed too often within the configured window.
+type CrashLoopDetector struct {
+	dockerService *DockerService
+	config        *internal.AppConfig
+
+	mu         sync.Mutex
+	containers map[string]*containerCrashes
+}
+
+func NewCrashLoopDetector(dockerService *DockerService, config *internal.AppConfig) *CrashLoopDetector {
+	return &CrashLoopDetector{
+		dockerService: dockerService,
+		config:        config,
+		containers:    make(map[string]*containerCrashes),
+	}
+}
+
+// Run follows the Docker events until the context is done, subscribing
+// again whenever the subscription fails. Events of the last window are
+// replayed on every subscription, so crashes before startup are known.
+func (d *CrashLoopDetector) Run(ctx context.Context) {
+	for {
+		err := d.follow(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Error().Err(err).Msg("failed to follow container events")
+
+		select {
+		case <-time.After(crashLoopReconnectDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// follow processes the events of service containers until the subscription
+// fails or the context is done.
+func (d *CrashLoopDetector) follow(ctx context.Context) error {
+	// replayed events must not be counted twice
+	d.mu.Lock()
+	clear(d.containers)
+	d.mu.Unlock()
+
+	filters := make(client.Filters).
+		Add("type", string(events.ContainerEventType)).
+		Add("label", "gidock.service=true").
+		Add("event", string(events.ActionKill), string(events.ActionDie), string(events.ActionDestroy))
+	result := d.dockerService.client.Events(ctx, client.EventsListOptions{
+		Since:   strconv.FormatInt(time.Now().Add(-d.config.CrashLoopWindow).Unix(), 10),
+		Filters: filters,
+	})
+
+	for {
+		select {
+		case message := <-result.Messages:
+			d.handleEvent(message)
+		case err := <-result.Err:
+			if err == nil {
+				err = errors.New("event stream closed")
+			}
+			return err
+		}
+	}
+}
+
+// handleEvent records a container exit as a crash, unless the container was
+// killed on purpose before. Only kills with a terminating signal count, since
+// other signals (e.g. SIGHUP to reload the configuration) don't stop it.
+func (d *CrashLoopDetector) handleEvent(message events.Message) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	containerID := message.Actor.ID
+	if message.Action == events.ActionDestroy {
+		delete(d.containers, containerID)
+		return
+	}
+
+	tracked, ok := d.containers[containerID]
+	if !ok {
+		tracked = &containerCrashes{}
+		d.containers[containerID] = tracked
+	}
+
+	switch message.Action {
+	case events.ActionKill:
+		if isStopSignal(message.Actor.Attributes["signal"]) {
+			tracked.stopping = true
+		}
+	case events.ActionDie:
+		if tracked.stopping {
+			tracked.stopping = false
+			return
+		}
+		tracked.crashes = append(tracked.crashes, time.Unix(0, message.TimeNano))
+	}
+}
+
+// isStopSignal reports whether the signal of a kill event (its number, as
+// reported by Docker) is SIGTERM or SIGKILL.
+func isStopSignal(signal string) bool {
+	number, err := strconv.Atoi(signal)
+	if err != nil {
+		return false
+	}
+	return syscall.Signal(number) == syscall.SIGTERM || syscall.Signal(number) == syscall.SIGKILL
+}
+
+// IsCrashLooping reports whether the container crashed at least the
+// configured number of times within the configured window.
+func (d *CrashLoopDetector) IsCrashLooping(containerID string) bool {
+	if d.config.CrashLoopRestarts <= 0 {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tracked, ok := d.containers[containerID]
+	if !ok {
+		return false
+	}
+
+	// forget crashes that have left the window
+	windowStart := time.Now().Add(-d.config.CrashLoopWindow)
+	recent := tracked.crashes[:0]
+	for _, crashedAt := range tracked.crashes {
+		if crashedAt.After(windowStart) {
+			recent = append(recent, crashedAt)
+		}
+	}
+	tracked.crashes = recent
+
+	return len(tracked.crashes) >= d.config.CrashLoopRestarts
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Pelfox/gidock/internal"
+	"github.com/moby/moby/api/types/events"
+)
+
+func TestCrashLoopDetector(t *testing.T) {
+	type event struct {
+		action events.Action
+		// age is how long ago the event happened
+		age time.Duration
+		// signal is the signal number of a kill event
+		signal string
+	}
+	die := func(age time.Duration) event { return event{action: events.ActionDie, age: age} }
+	kill := func(age time.Duration) event { return event{action: events.ActionKill, age: age, signal: "15"} }
+	destroy := event{action: events.ActionDestroy}
+
+	tests := []struct {
+		name     string
+		restarts int
+		events   []event
+		want     bool
+	}{
+		{name: "no events", restarts: 3, want: false},
+		{name: "too few crashes", restarts: 3, events: []event{die(time.Second), die(time.Second)}, want: false},
+		{
+			name:     "enough crashes",
+			restarts: 3,
+			events:   []event{die(3 * time.Second), die(2 * time.Second), die(time.Second)},
+			want:     true,
+		},
+		{
+			name:     "crashes outside of the window",
+			restarts: 3,
+			events:   []event{die(2 * time.Minute), die(2 * time.Second), die(time.Second)},
+			want:     false,
+		},
+		{
+			name:     "exits after a kill are no crashes",
+			restarts: 3,
+			events:   []event{die(4 * time.Second), kill(3 * time.Second), die(3 * time.Second), die(time.Second)},
+			want:     false,
+		},
+		{
+			name:     "exits after SIGKILL are no crashes",
+			restarts: 2,
+			events:   []event{die(4 * time.Second), {events.ActionKill, 3 * time.Second, "9"}, die(3 * time.Second)},
+			want:     false,
+		},
+		{
+			name:     "non-terminating signals don't excuse the next exit",
+			restarts: 2,
+			events:   []event{{events.ActionKill, 3 * time.Second, "1"}, die(2 * time.Second), die(time.Second)},
+			want:     true,
+		},
+		{
+			name:     "a kill only excuses the next exit",
+			restarts: 2,
+			events:   []event{kill(3 * time.Second), die(3 * time.Second), die(2 * time.Second), die(time.Second)},
+			want:     true,
+		},
+		{
+			name:     "destroyed containers are forgotten",
+			restarts: 3,
+			events:   []event{die(3 * time.Second), die(2 * time.Second), die(time.Second), destroy},
+			want:     false,
+		},
+		{
+			name:     "detection disabled",
+			restarts: 0,
+			events:   []event{die(3 * time.Second), die(2 * time.Second), die(time.Second)},
+			want:     false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			detector := NewCrashLoopDetector(nil, &internal.AppConfig{
+				CrashLoopRestarts: test.restarts,
+				CrashLoopWindow:   time.Minute,
+			})
+			for _, e := range test.events {
+				detector.handleEvent(events.Message{
+					Action:   e.action,
+					Actor:    events.Actor{ID: "container", Attributes: map[string]string{"signal": e.signal}},
+					TimeNano: time.Now().Add(-e.age).UnixNano(),
+				})
+			}
+			// events of other containers must not count
+			detector.handleEvent(events.Message{Action: events.ActionDie, Actor: events.Actor{ID: "other"}, TimeNano: time.Now().UnixNano()})
+
+			if got := detector.IsCrashLooping("container"); got != test.want {
+				t.Errorf("IsCrashLooping() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
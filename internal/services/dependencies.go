@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Pelfox/gidock/internal"
+	"github.com/Pelfox/gidock/internal/models"
+	"github.com/Pelfox/gidock/internal/repositories/commands"
+	"github.com/google/uuid"
+)
+
+// resolvedDependency is a dependency service together with the strictest
+// condition required from it by any of its dependents.
+type resolvedDependency struct {
+	Service   models.Service
+	Condition models.ServiceCondition
+}
+
+// dependencyLookup returns the service with the given ID, which is
+// referenced as a dependency by `dependent`.
+type dependencyLookup func(id uuid.UUID, dependent models.Service) (*models.Service, error)
+
+// dependencyResolver walks the dependency graph of a service and produces
+// a topological start order.
+type dependencyResolver struct {
+	lookup     dependencyLookup
+	visited    map[uuid.UUID]bool
+	visiting   map[uuid.UUID]bool
+	path       []models.Service
+	conditions map[uuid.UUID]models.ServiceCondition
+	order      []models.Service
+}
+
+// newDependencyResolver creates a new dependencyResolver that fetches
+// dependency services with the given lookup function.
+func newDependencyResolver(lookup dependencyLookup) *dependencyResolver {
+	return &dependencyResolver{
+		lookup:     lookup,
+		visited:    make(map[uuid.UUID]bool),
+		visiting:   make(map[uuid.UUID]bool),
+		conditions: make(map[uuid.UUID]models.ServiceCondition),
+	}
+}
+
+// graphLookup creates a dependencyLookup that resolves services from an
+// in-memory graph of the given project.
+func graphLookup(graph map[uuid.UUID]models.Service, projectID uuid.UUID) dependencyLookup {
+	return func(id uuid.UUID, dependent models.Service) (*models.Service, error) {
+		dependency, ok := graph[id]
+		if !ok {
+			return nil, fmt.Errorf(
+				"%w: service %s referenced by %q does not exist in project %s",
+				internal.ErrInvalidDependency,
+				id,
+				dependent.Name,
+				projectID,
+			)
+		}
+		return &dependency, nil
+	}
+}
+
+// resolveDependencies returns every (transitive) dependency of the given
+// service in the order they must be started. The service itself is not
+// included in the result.
+func (s *ServiceService) resolveDependencies(
+	ctx context.Context,
+	service *models.Service,
+) ([]resolvedDependency, error) {
+	resolver := newDependencyResolver(func(id uuid.UUID, dependent models.Service) (*models.Service, error) {
+		dependency, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get dependency %s of service %q: %w", id, dependent.Name, err)
+		}
+		return dependency, nil
+	})
+	if err := resolver.visit(*service); err != nil {
+		return nil, err
+	}
+
+	// the last element is always the root service itself
+	order := resolver.order[:len(resolver.order)-1]
+	result := make([]resolvedDependency, 0, len(order))
+	for _, dependency := range order {
+		result = append(result, resolvedDependency{
+			Service:   dependency,
+			Condition: resolver.conditions[dependency.ID],
+		})
+	}
+	return result, nil
+}
+
+// resolveProjectOrder returns every service of the project in the order they
+// must be started. The condition of each entry is the strictest condition
+// required by its dependents, or empty if no service depends on it.
+func (s *ServiceService) resolveProjectOrder(
+	ctx context.Context,
+	projectID uuid.UUID,
+) ([]resolvedDependency, error) {
+	projectServices, err := s.serviceRepository.ListByProject(
+		ctx,
+		commands.ListProjectServicesCommand{ProjectID: projectID},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := make(map[uuid.UUID]models.Service, len(projectServices))
+	for _, projectService := range projectServices {
+		graph[projectService.ID] = projectService
+	}
+
+	resolver := newDependencyResolver(graphLookup(graph, projectID))
+	for _, projectService := range projectServices {
+		if err := resolver.visit(projectService); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]resolvedDependency, 0, len(resolver.order))
+	for _, service := range resolver.order {
+		result = append(result, resolvedDependency{
+			Service:   service,
+			Condition: resolver.conditions[service.ID],
+		})
+	}
+	return result, nil
+}
+
+// validateDependencies checks that every dependency of the given service
+// references an existing service of the same project with a valid
+// condition, and that the resulting dependency graph is acyclic.
+func (s *ServiceService) validateDependencies(ctx context.Context, service models.Service) error {
+	projectServices, err := s.serviceRepository.ListByProject(
+		ctx,
+		commands.ListProjectServicesCommand{ProjectID: service.ProjectID},
+	)
+	if err != nil {
+		return err
+	}
+
+	// build the graph as it would look like after the change is applied
+	graph := make(map[uuid.UUID]models.Service, len(projectServices)+1)
+	for _, projectService := range projectServices {
+		graph[projectService.ID] = projectService
+	}
+	graph[service.ID] = service
+
+	for _, dependency := range service.Dependencies {
+		if dependency.ServiceID == service.ID {
+			return fmt.Errorf("%w: service %q cannot depend on itself", internal.ErrInvalidDependency, service.Name)
+		}
+		if _, ok := graph[dependency.ServiceID]; !ok {
+			return fmt.Errorf(
+				"%w: service %s does not exist in project %s",
+				internal.ErrInvalidDependency,
+				dependency.ServiceID,
+				service.ProjectID,
+			)
+		}
+		if !dependency.Condition.IsValid() {
+			return fmt.Errorf(
+				"%w: unknown condition %q for dependency %s",
+				internal.ErrInvalidDependency,
+				dependency.Condition,
+				dependency.ServiceID,
+			)
+		}
+	}
+
+	resolver := newDependencyResolver(graphLookup(graph, service.ProjectID))
+	return resolver.visit(service)
+}
+
+// visit performs a depth-first traversal, appending services to the order
+// after all of their dependencies.
+func (r *dependencyResolver) visit(service models.Service) error {
+	if r.visited[service.ID] {
+		return nil
+	}
+	if r.visiting[service.ID] {
+		// report only the part of the path that forms the cycle
+		start := 0
+		for i, pathService := range r.path {
+			if pathService.ID == service.ID {
+				start = i
+				break
+			}
+		}
+		cycle := append(r.path[start:len(r.path):len(r.path)], service)
+		return fmt.Errorf("%w: %s", internal.ErrDependencyCycle, formatDependencyPath(cycle))
+	}
+	r.path = append(r.path, service)
+	r.visiting[service.ID] = true
+
+	for _, dependency := range service.Dependencies {
+		// keep the strictest condition requested by any dependent
+		if r.conditions[dependency.ServiceID] != models.ServiceConditionHealthy {
+			r.conditions[dependency.ServiceID] = dependency.Condition
+		}
+
+		dependencyService, err := r.lookup(dependency.ServiceID, service)
+		if err != nil {
+			return err
+		}
+		if err := r.visit(*dependencyService); err != nil {
+			return err
+		}
+	}
+
+	r.path = r.path[:len(r.path)-1]
+	r.visiting[service.ID] = false
+	r.visited[service.ID] = true
+	r.order = append(r.order, service)
+	return nil
+}
+
+// formatDependencyPath renders a dependency path as a human-readable chain,
+// e.g. `api -> worker -> api`.
+func formatDependencyPath(path []models.Service) string {
+	names := make([]string, len(path))
+	for i, service := range path {
+		names[i] = service.Name
+	}
+	return strings.Join(names, " -> ")
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Pelfox/gidock/internal"
+	"github.com/Pelfox/gidock/internal/models"
+	"github.com/google/uuid"
+)
+
+// testGraph builds a dependency graph from the services and their
+// dependencies (by name, all with the `ready` condition unless prefixed with
+// `healthy:`). It returns the graph and the services in definition order.
+func testGraph(definitions [][2]string) (map[uuid.UUID]models.Service, []models.Service) {
+	ids := make(map[string]uuid.UUID, len(definitions))
+	for _, definition := range definitions {
+		ids[definition[0]] = uuid.New()
+	}
+
+	graph := make(map[uuid.UUID]models.Service, len(definitions))
+	services := make([]models.Service, 0, len(definitions))
+	for _, definition := range definitions {
+		service := models.Service{ID: ids[definition[0]], Name: definition[0]}
+		for _, name := range strings.Fields(definition[1]) {
+			condition := models.ServiceConditionReady
+			if dependencyName, ok := strings.CutPrefix(name, "healthy:"); ok {
+				name = dependencyName
+				condition = models.ServiceConditionHealthy
+			}
+			id, ok := ids[name]
+			if !ok {
+				id = uuid.New()
+			}
+			service.Dependencies = append(service.Dependencies, models.ServiceDependency{
+				ServiceID: id,
+				Condition: condition,
+			})
+		}
+		graph[service.ID] = service
+		services = append(services, service)
+	}
+	return graph, services
+}
+
+func TestDependencyResolverOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		definitions [][2]string
+		want        []string
+		conditions  map[string]models.ServiceCondition
+	}{
+		{
+			name:        "independent services",
+			definitions: [][2]string{{"api", ""}, {"db", ""}},
+			want:        []string{"api", "db"},
+		},
+		{
+			name:        "chain",
+			definitions: [][2]string{{"web", "api"}, {"api", "db"}, {"db", ""}},
+			want:        []string{"db", "api", "web"},
+			conditions:  map[string]models.ServiceCondition{"db": "ready", "api": "ready", "web": ""},
+		},
+		{
+			name:        "diamond",
+			definitions: [][2]string{{"web", "api worker"}, {"api", "healthy:db"}, {"worker", "db"}, {"db", ""}},
+			want:        []string{"db", "api", "worker", "web"},
+			conditions:  map[string]models.ServiceCondition{"db": "healthy"},
+		},
+		{
+			name:        "strictest condition is kept",
+			definitions: [][2]string{{"api", "healthy:db"}, {"worker", "db"}, {"db", ""}},
+			want:        []string{"db", "api", "worker"},
+			conditions:  map[string]models.ServiceCondition{"db": "healthy"},
+		},
+		{
+			name:        "later strictest condition upgrades an earlier one",
+			definitions: [][2]string{{"worker", "db"}, {"api", "healthy:db"}, {"db", ""}},
+			want:        []string{"db", "worker", "api"},
+			conditions:  map[string]models.ServiceCondition{"db": "healthy"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			graph, services := testGraph(test.definitions)
+			resolver := newDependencyResolver(graphLookup(graph, uuid.New()))
+			for _, service := range services {
+				if err := resolver.visit(service); err != nil {
+					t.Fatalf("visit(%q) error = %v", service.Name, err)
+				}
+			}
+
+			got := make([]string, len(resolver.order))
+			for i, service := range resolver.order {
+				got[i] = service.Name
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("order = %q, want %q", got, test.want)
+			}
+
+			for _, service := range services {
+				want, ok := test.conditions[service.Name]
+				if !ok {
+					continue
+				}
+				if got := resolver.conditions[service.ID]; got != want {
+					t.Errorf("condition of %q = %q, want %q", service.Name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDependencyResolverErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		definitions [][2]string
+		wantErr     error
+		wantPath    string
+	}{
+		{
+			name:        "self dependency",
+			definitions: [][2]string{{"api", "api"}},
+			wantErr:     internal.ErrDependencyCycle,
+			wantPath:    "api -> api",
+		},
+		{
+			name:        "cycle",
+			definitions: [][2]string{{"api", "worker"}, {"worker", "api"}},
+			wantErr:     internal.ErrDependencyCycle,
+			wantPath:    "api -> worker -> api",
+		},
+		{
+			name:        "cycle below an acyclic prefix",
+			definitions: [][2]string{{"web", "api"}, {"api", "worker"}, {"worker", "queue"}, {"queue", "api"}},
+			wantErr:     internal.ErrDependencyCycle,
+			wantPath:    "api -> worker -> queue -> api",
+		},
+		{
+			name:        "unknown dependency",
+			definitions: [][2]string{{"api", "missing"}},
+			wantErr:     internal.ErrInvalidDependency,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			graph, services := testGraph(test.definitions)
+			resolver := newDependencyResolver(graphLookup(graph, uuid.New()))
+			err := resolver.visit(services[0])
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("visit() error = %v, want %v", err, test.wantErr)
+			}
+			if test.wantPath != "" && !strings.HasSuffix(err.Error(), ": "+test.wantPath) {
+				t.Errorf("visit() error = %q, want path %q", err, test.wantPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/Pelfox/gidock/internal"
+	"github.com/moby/moby/api/pkg/stdcopy"
+	"github.com/moby/moby/client"
+)
+
+const (
+	// execExitTimeout is the maximum time to wait for an exec process to be
+	// marked as exited after its output ended.
+	execExitTimeout = 5 * time.Second
+	// execExitPollInterval is the interval at which the state of an exec
+	// process is checked while waiting for it to exit.
+	execExitPollInterval = 100 * time.Millisecond
+)
+
+// ExecSession is a process running inside a container. For interactive
+// processes with a TTY, reading returns the terminal output and writing sends
+// input; the output of non-interactive processes is collected with `Wait`.
+type ExecSession struct {
+	client *client.Client
+	id     string
+	stream client.HijackedResponse
+}
+
+// StartExecSession starts the command with a TTY of the given size in the
+// container and attaches to it. The caller must close the session.
+func (s *DockerService) StartExecSession(
+	ctx context.Context,
+	containerID string,
+	command []string,
+	height uint,
+	width uint,
+) (*ExecSession, error) {
+	consoleSize := client.ConsoleSize{Height: height, Width: width}
+	createResult, err := s.client.ExecCreate(ctx, containerID, client.ExecCreateOptions{
+		TTY:          true,
+		ConsoleSize:  consoleSize,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          command,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	attachResult, err := s.client.ExecAttach(ctx, createResult.ID, client.ExecAttachOptions{
+		TTY:         true,
+		ConsoleSize: consoleSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExecSession{client: s.client, id: createResult.ID, stream: attachResult.HijackedResponse}, nil
+}
+
+// StartExecCommand starts a non-interactive command in the container and
+// attaches to its output. The caller must close the session.
+func (s *DockerService) StartExecCommand(
+	ctx context.Context,
+	containerID string,
+	command []string,
+	workingDir string,
+) (*ExecSession, error) {
+	createResult, err := s.client.ExecCreate(ctx, containerID, client.ExecCreateOptions{
+		AttachStdout: true,
+		AttachStderr: true,
+		WorkingDir:   workingDir,
+		Cmd:          command,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	attachResult, err := s.client.ExecAttach(ctx, createResult.ID, client.ExecAttachOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExecSession{client: s.client, id: createResult.ID, stream: attachResult.HijackedResponse}, nil
+}
+
+// Wait copies the demultiplexed output of a non-interactive process to the
+// writers until it exits, and returns its exit code. If the context is done
+// first, it detaches and returns `internal.ErrCommandTimeout`; Docker can't
+// stop exec processes, so the process itself keeps running.
+func (e *ExecSession) Wait(ctx context.Context, stdout io.Writer, stderr io.Writer) (int, error) {
+	copyDone := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(stdout, stderr, e.stream.Reader)
+		copyDone <- err
+	}()
+
+	select {
+	case err := <-copyDone:
+		if err != nil {
+			return 0, err
+		}
+	case <-ctx.Done():
+		// closing the stream ends the copy, so the writers are no longer
+		// used once this returns
+		e.stream.Close()
+		if err := <-copyDone; err != nil && !errors.Is(err, net.ErrClosed) {
+			return 0, err
+		}
+		return 0, internal.ErrCommandTimeout
+	}
+
+	return e.ExitCode(context.WithoutCancel(ctx))
+}
+
+// Read reads the terminal output of the process.
+func (e *ExecSession) Read(p []byte) (int, error) {
+	return e.stream.Reader.Read(p)
+}
+
+// Write writes input to the terminal of the process.
+func (e *ExecSession) Write(p []byte) (int, error) {
+	return e.stream.Conn.Write(p)
+}
+
+// Resize changes the size of the terminal.
+func (e *ExecSession) Resize(ctx context.Context, height uint, width uint) error {
+	_, err := e.client.ExecResize(ctx, e.id, client.ExecResizeOptions{Height: height, Width: width})
+	return err
+}
+
+// ExitCode returns the exit code of the process. Docker may report the
+// process as running for a moment after its output ended, so it waits up to
+// `execExitTimeout` for the process to be marked as exited.
+func (e *ExecSession) ExitCode(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, execExitTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(execExitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		inspectResult, err := e.client.ExecInspect(ctx, e.id, client.ExecInspectOptions{})
+		if err != nil {
+			return 0, err
+		}
+		if !inspectResult.Running {
+			return inspectResult.ExitCode, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// Close detaches from the process. Closing the terminal hangs up the
+// process, which usually terminates it.
+func (e *ExecSession) Close() error {
+	e.stream.Close()
+	return nil
+}
+
+var _ io.ReadWriteCloser = (*ExecSession)(nil)
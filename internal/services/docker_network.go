@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/containerd/errdefs"
+	"github.com/google/uuid"
+	"github.com/moby/moby/client"
+)
+
+// dnsAliasPattern matches runs of characters not allowed in DNS labels.
+var dnsAliasPattern = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// projectNetworkName returns the name of the user-defined network of the
+// project, which gives services with network access external connectivity.
+func projectNetworkName(projectID uuid.UUID) string {
+	return "gidock-" + projectID.String()
+}
+
+// projectInternalNetworkName returns the name of the internal network of the
+// project, which every service joins with its DNS alias.
+func projectInternalNetworkName(projectID uuid.UUID) string {
+	return "gidock-" + projectID.String() + "-internal"
+}
+
+// serviceDNSAlias derives the DNS alias of a service within its project
+// network from the service name, e.g. `My API` becomes `my-api`.
+func serviceDNSAlias(name string) string {
+	alias := dnsAliasPattern.ReplaceAllString(strings.ToLower(name), "-")
+	return strings.Trim(alias, "-")
+}
+
+// EnsureProjectNetwork creates the bridge network of the project, unless it
+// already exists, and returns its name.
+func (s *DockerService) EnsureProjectNetwork(ctx context.Context, projectID uuid.UUID) (string, error) {
+	name := projectNetworkName(projectID)
+	return name, s.ensureNetwork(ctx, name, projectID, false)
+}
+
+// EnsureProjectInternalNetwork creates the internal bridge network of the
+// project, unless it already exists, and returns its name. Containers in it
+// can only reach each other.
+func (s *DockerService) EnsureProjectInternalNetwork(ctx context.Context, projectID uuid.UUID) (string, error) {
+	name := projectInternalNetworkName(projectID)
+	return name, s.ensureNetwork(ctx, name, projectID, true)
+}
+
+// ensureNetwork creates the bridge network of the project with the given
+// name, unless it already exists.
+func (s *DockerService) ensureNetwork(ctx context.Context, name string, projectID uuid.UUID, internal bool) error {
+	_, err := s.client.NetworkInspect(ctx, name, client.NetworkInspectOptions{})
+	if err == nil {
+		return nil
+	}
+	if !errdefs.IsNotFound(err) {
+		return err
+	}
+
+	_, err = s.client.NetworkCreate(ctx, name, client.NetworkCreateOptions{
+		Driver:   "bridge",
+		Internal: internal,
+		Labels: map[string]string{
+			"gidock.network":    "true",
+			"gidock.project_id": projectID.String(),
+		},
+	})
+	// the network might have been created concurrently by another service
+	if err != nil && !errdefs.IsConflict(err) {
+		return err
+	}
+	return nil
+}
+
+// RemoveProjectNetwork removes the networks of the project. Removing a
+// network that doesn't exist is not an error.
+func (s *DockerService) RemoveProjectNetwork(ctx context.Context, projectID uuid.UUID) error {
+	for _, name := range []string{projectNetworkName(projectID), projectInternalNetworkName(projectID)} {
+		_, err := s.client.NetworkRemove(ctx, name, client.NetworkRemoveOptions{})
+		if err != nil && !errdefs.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,7 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Pelfox/gidock/internal"
 	"github.com/Pelfox/gidock/internal/dto"
 	"github.com/Pelfox/gidock/internal/models"
 	"github.com/Pelfox/gidock/pkg"
@@ -12,7 +19,9 @@ import (
 	"github.com/moby/moby/api/pkg/stdcopy"
 	"github.com/moby/moby/api/types/container"
 	"github.com/moby/moby/api/types/mount"
+	"github.com/moby/moby/api/types/network"
 	"github.com/moby/moby/client"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
@@ -25,7 +34,13 @@ func NewDockerService(dockerClient *client.Client) *DockerService {
 }
 
 func (s *DockerService) PullServiceImage(ctx context.Context, service *models.Service) error {
-	_, err := s.client.ImageInspect(ctx, service.Image)
+	logger := log.With().Str("service_id", service.ID.String()).Logger()
+	return s.pullImage(ctx, service.Image, logger)
+}
+
+// pullImage pulls the image, unless it already exists locally.
+func (s *DockerService) pullImage(ctx context.Context, image string, logger zerolog.Logger) error {
+	_, err := s.client.ImageInspect(ctx, image)
 
 	// image already exists - no need to pull
 	if err == nil {
@@ -36,12 +51,10 @@ func (s *DockerService) PullServiceImage(ctx context.Context, service *models.Se
 		return err
 	}
 
-	log.Info().Str("service_id", service.ID.String()).
-		Str("image", service.Image).
-		Msg("pulling image for service")
+	logger.Info().Str("image", image).Msg("pulling image")
 
 	// TODO: support for auth
-	pullResult, err := s.client.ImagePull(ctx, service.Image, client.ImagePullOptions{})
+	pullResult, err := s.client.ImagePull(ctx, image, client.ImagePullOptions{})
 	if err != nil {
 		return err
 	}
@@ -60,22 +73,19 @@ func (s *DockerService) CreateServiceContainer(ctx context.Context, service *mod
 		environment = append(environment, fmt.Sprintf("%s=%s", key, value))
 	}
 
-	mounts := make([]mount.Mount, len(service.Mounts))
+	mounts := make([]mount.Mount, 0, len(service.Mounts))
 	for _, serviceMount := range service.Mounts {
-		mounts = append(mounts, mount.Mount{
-			Type:     mount.TypeVolume,
-			Source:   serviceMount.Source,
-			Target:   serviceMount.Target,
-			ReadOnly: serviceMount.ReadOnly,
-		})
+		mounts = append(mounts, buildMount(serviceMount))
 	}
 
+	exposedPorts, portBindings := buildPortBindings(service.Ports)
+
 	createOptions := client.ContainerCreateOptions{
 		// TODO: we should probably support more fields from this struct
-		// TODO: scan `dependencies` from `service` and attach to them
 		Config: &container.Config{
-			Env:             environment,
-			NetworkDisabled: !service.NetworkAccess,
+			Env:          environment,
+			ExposedPorts: exposedPorts,
+			Healthcheck:  buildHealthConfig(service.HealthCheck),
 			Labels: map[string]string{
 				"gidock.service":    "true",
 				"gidock.service_id": service.ID.String(),
@@ -83,11 +93,46 @@ func (s *DockerService) CreateServiceContainer(ctx context.Context, service *mod
 			},
 		},
 		HostConfig: &container.HostConfig{
-			Mounts: mounts,
+			Mounts:       mounts,
+			PortBindings: portBindings,
 		},
 		// TODO: specify `Name`, when `models.Service` model will be updated to support it
 		Image: service.Image,
 	}
+
+	if service.Resources != nil {
+		createOptions.HostConfig.Resources = buildResources(*service.Resources)
+		createOptions.HostConfig.ShmSize = service.Resources.ShmSize
+	}
+	if service.RestartPolicy != nil {
+		createOptions.HostConfig.RestartPolicy = container.RestartPolicy{
+			Name:              container.RestartPolicyMode(service.RestartPolicy.Name),
+			MaximumRetryCount: service.RestartPolicy.MaxRetries,
+		}
+	}
+
+	// every service joins the internal project network, where other services
+	// can reach it by its DNS alias; only services with network access also
+	// join the project bridge network for external connectivity
+	internalNetworkName, err := s.EnsureProjectInternalNetwork(ctx, service.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	createOptions.HostConfig.NetworkMode = container.NetworkMode(internalNetworkName)
+	createOptions.NetworkingConfig = &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			internalNetworkName: {Aliases: []string{serviceDNSAlias(service.Name)}},
+		},
+	}
+	if service.NetworkAccess {
+		networkName, err := s.EnsureProjectNetwork(ctx, service.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		createOptions.HostConfig.NetworkMode = container.NetworkMode(networkName)
+		createOptions.NetworkingConfig.EndpointsConfig[networkName] = &network.EndpointSettings{}
+	}
+
 	createResult, err := s.client.ContainerCreate(ctx, createOptions)
 	if err != nil {
 		return nil, err
@@ -96,29 +141,106 @@ func (s *DockerService) CreateServiceContainer(ctx context.Context, service *mod
 	return &createResult.ID, nil
 }
 
-func (s *DockerService) StartServiceContainer(
-	ctx context.Context,
-	containerID string,
-	service *models.Service,
-) (*string, error) {
-	startOptions := client.ContainerStartOptions{}
-	_, err := s.client.ContainerStart(ctx, containerID, startOptions)
+// buildMount maps the service mount to the Docker mount configuration. The
+// mount is expected to be validated already.
+func buildMount(serviceMount models.ServiceMount) mount.Mount {
+	result := mount.Mount{
+		Type:     mount.TypeVolume,
+		Source:   serviceMount.Source,
+		Target:   serviceMount.Target,
+		ReadOnly: serviceMount.ReadOnly,
+	}
 
-	// if container isn't found, create a new one and start it
-	if errdefs.IsNotFound(err) {
-		containerID, err := s.CreateServiceContainer(ctx, service)
-		if err != nil {
-			return nil, err
+	switch serviceMount.Type {
+	case models.MountTypeBind:
+		result.Type = mount.TypeBind
+		if serviceMount.BindPropagation != "" {
+			result.BindOptions = &mount.BindOptions{
+				Propagation: mount.Propagation(serviceMount.BindPropagation),
+			}
+		}
+	case models.MountTypeTmpfs:
+		result.Type = mount.TypeTmpfs
+		result.TmpfsOptions = &mount.TmpfsOptions{SizeBytes: serviceMount.TmpfsSize}
+		if mode, err := strconv.ParseUint(serviceMount.TmpfsMode, 8, 32); err == nil {
+			result.TmpfsOptions.Mode = os.FileMode(mode)
 		}
+	}
 
-		_, err = s.client.ContainerStart(ctx, *containerID, startOptions)
-		if err != nil {
-			return nil, err
+	return result
+}
+
+// buildPortBindings maps the service ports to the exposed ports and host
+// port bindings of the container. The ports are expected to be validated
+// already.
+func buildPortBindings(ports []models.ServicePort) (network.PortSet, network.PortMap) {
+	exposedPorts := make(network.PortSet, len(ports))
+	portBindings := make(network.PortMap, len(ports))
+	for _, servicePort := range ports {
+		port, ok := network.PortFrom(uint16(servicePort.ContainerPort), network.IPProtocol(servicePort.PortProtocol()))
+		if !ok {
+			continue
+		}
+
+		binding := network.PortBinding{}
+		if servicePort.HostPort != 0 {
+			binding.HostPort = strconv.Itoa(servicePort.HostPort)
+		}
+		if servicePort.HostIP != "" {
+			binding.HostIP, _ = netip.ParseAddr(servicePort.HostIP)
 		}
-		return containerID, nil
+
+		exposedPorts[port] = struct{}{}
+		portBindings[port] = append(portBindings[port], binding)
 	}
+	return exposedPorts, portBindings
+}
 
-	return &containerID, err
+// buildResources maps the service resource limits to the Docker resource
+// configuration. The limits are expected to be validated already.
+func buildResources(resources models.ServiceResources) container.Resources {
+	result := container.Resources{
+		Memory:            resources.MemoryLimit,
+		MemoryReservation: resources.MemoryReservation,
+		NanoCPUs:          resources.NanoCPUs,
+		CPUQuota:          resources.CPUQuota,
+		CPUPeriod:         resources.CPUPeriod,
+	}
+	if resources.PidsLimit != 0 {
+		result.PidsLimit = &resources.PidsLimit
+	}
+	return result
+}
+
+// buildHealthConfig maps the service health check to the Docker health
+// check configuration. It returns nil (inheriting the image health check)
+// when no health check is defined.
+func buildHealthConfig(healthCheck *models.ServiceHealthCheck) *container.HealthConfig {
+	if healthCheck == nil {
+		return nil
+	}
+	if len(healthCheck.Command) == 0 {
+		return &container.HealthConfig{Test: []string{"NONE"}}
+	}
+
+	test := append([]string{"CMD"}, healthCheck.Command...)
+	if len(healthCheck.Command) == 1 {
+		test = []string{"CMD-SHELL", healthCheck.Command[0]}
+	}
+
+	return &container.HealthConfig{
+		Test:        test,
+		Interval:    time.Duration(healthCheck.Interval) * time.Second,
+		Timeout:     time.Duration(healthCheck.Timeout) * time.Second,
+		Retries:     healthCheck.Retries,
+		StartPeriod: time.Duration(healthCheck.StartPeriod) * time.Second,
+	}
+}
+
+// StartContainer starts the existing container.
+func (s *DockerService) StartContainer(ctx context.Context, containerID string) error {
+	_, err := s.client.ContainerStart(ctx, containerID, client.ContainerStartOptions{})
+	return err
 }
 
 func (s *DockerService) StopContainer(ctx context.Context, containerID string, kill bool) error {
@@ -137,6 +259,49 @@ func (s *DockerService) StopContainer(ctx context.Context, containerID string, k
 	return err
 }
 
+// RestartContainer restarts the container. The container is given `timeout`
+// seconds to stop gracefully (Docker's default is used if it is nil) and is
+// sent SIGKILL instead of SIGTERM if `kill` is set.
+func (s *DockerService) RestartContainer(ctx context.Context, containerID string, timeout *int, kill bool) error {
+	signal := "SIGTERM"
+	if kill {
+		signal = "SIGKILL"
+	}
+
+	_, err := s.client.ContainerRestart(ctx, containerID, client.ContainerRestartOptions{
+		Signal:  signal,
+		Timeout: timeout,
+	})
+	return err
+}
+
+// PauseContainer suspends all processes in the container.
+func (s *DockerService) PauseContainer(ctx context.Context, containerID string) error {
+	_, err := s.client.ContainerPause(ctx, containerID, client.ContainerPauseOptions{})
+	return err
+}
+
+// UnpauseContainer resumes all processes in a paused container.
+func (s *DockerService) UnpauseContainer(ctx context.Context, containerID string) error {
+	_, err := s.client.ContainerUnpause(ctx, containerID, client.ContainerUnpauseOptions{})
+	return err
+}
+
+// RemoveContainer forcibly removes the container. Anonymous volumes of the
+// container are removed as well if `removeVolumes` is set. Removing a
+// container that no longer exists is not an error.
+func (s *DockerService) RemoveContainer(ctx context.Context, containerID string, removeVolumes bool) error {
+	_, err := s.client.ContainerRemove(ctx, containerID, client.ContainerRemoveOptions{
+		RemoveVolumes: removeVolumes,
+		Force:         true,
+	})
+
+	if errdefs.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
 func (s *DockerService) GetContainerStatus(ctx context.Context, containerID string) (*dto.ServiceStatusResponse, error) {
 	inspectResult, err := s.client.ContainerInspect(ctx, containerID, client.ContainerInspectOptions{})
 	if err != nil {
@@ -144,19 +309,220 @@ func (s *DockerService) GetContainerStatus(ctx context.Context, containerID stri
 	}
 	return &dto.ServiceStatusResponse{
 		State:      inspectResult.Container.State.Status,
+		Paused:     inspectResult.Container.State.Paused,
 		ExitCode:   inspectResult.Container.State.ExitCode,
 		StartedAt:  inspectResult.Container.State.StartedAt,
 		FinishedAt: inspectResult.Container.State.FinishedAt,
+		Ports:      boundPorts(inspectResult.Container.NetworkSettings),
 	}, nil
 }
 
-func (s *DockerService) GetContainerLogs(ctx context.Context, containerID string) (<-chan pkg.LogEntry, error) {
+// boundPorts returns the host ports the container ports are actually bound
+// to, sorted by container port.
+func boundPorts(settings *container.NetworkSettings) []dto.PortBinding {
+	result := make([]dto.PortBinding, 0)
+	if settings == nil {
+		return result
+	}
+	for port, bindings := range settings.Ports {
+		for _, binding := range bindings {
+			hostPort, err := strconv.Atoi(binding.HostPort)
+			if err != nil {
+				continue
+			}
+			result = append(result, dto.PortBinding{
+				ContainerPort: int(port.Num()),
+				Protocol:      string(port.Proto()),
+				HostIP:        formatAddr(binding.HostIP),
+				HostPort:      hostPort,
+			})
+		}
+	}
+	slices.SortFunc(result, func(a, b dto.PortBinding) int {
+		if a.ContainerPort != b.ContainerPort {
+			return a.ContainerPort - b.ContainerPort
+		}
+		return strings.Compare(a.Protocol+a.HostIP, b.Protocol+b.HostIP)
+	})
+	return result
+}
+
+// WaitForCondition blocks until the container satisfies the given condition,
+// the container exits or the context is done. For `ServiceConditionReady`
+// the container must be running; for `ServiceConditionHealthy` its health
+// check must report healthy.
+func (s *DockerService) WaitForCondition(
+	ctx context.Context,
+	containerID string,
+	condition models.ServiceCondition,
+) error {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		inspectResult, err := s.client.ContainerInspect(ctx, containerID, client.ContainerInspectOptions{})
+		if err != nil {
+			return err
+		}
+
+		state := inspectResult.Container.State
+		switch state.Status {
+		case container.StateExited, container.StateDead:
+			return fmt.Errorf("%w with code %d", internal.ErrContainerExited, state.ExitCode)
+		}
+
+		switch condition {
+		case models.ServiceConditionHealthy:
+			if state.Health == nil {
+				return internal.ErrNoHealthCheck
+			}
+			if state.Running && state.Health.Status == container.Healthy {
+				return nil
+			}
+		default:
+			if state.Running {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetContainerHealth returns the health of the container with at most
+// `limit` of its most recent probe results.
+func (s *DockerService) GetContainerHealth(
+	ctx context.Context,
+	containerID string,
+	limit int,
+) (*dto.ServiceHealthResponse, error) {
+	inspectResult, err := s.client.ContainerInspect(ctx, containerID, client.ContainerInspectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	state := inspectResult.Container.State
+	if state == nil || state.Health == nil {
+		return nil, internal.ErrNoHealthCheck
+	}
+
+	probes := state.Health.Log
+	if len(probes) > limit {
+		probes = probes[len(probes)-limit:]
+	}
+
+	response := &dto.ServiceHealthResponse{
+		Status:        state.Health.Status,
+		FailingStreak: state.Health.FailingStreak,
+		Probes:        make([]dto.HealthProbeResult, 0, len(probes)),
+	}
+	for _, probe := range probes {
+		if probe == nil {
+			continue
+		}
+		response.Probes = append(response.Probes, dto.HealthProbeResult{
+			Start:    probe.Start,
+			End:      probe.End,
+			ExitCode: probe.ExitCode,
+			Output:   probe.Output,
+		})
+	}
+	return response, nil
+}
+
+// InspectContainer returns a curated view of the container details.
+func (s *DockerService) InspectContainer(
+	ctx context.Context,
+	containerID string,
+) (*dto.ServiceContainerResponse, error) {
+	inspectResult, err := s.client.ContainerInspect(ctx, containerID, client.ContainerInspectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	inspect := inspectResult.Container
+
+	response := &dto.ServiceContainerResponse{
+		ID:           inspect.ID,
+		Name:         strings.TrimPrefix(inspect.Name, "/"),
+		ImageDigest:  inspect.Image,
+		CreatedAt:    inspect.Created,
+		RestartCount: inspect.RestartCount,
+		ExposedPorts: make([]string, 0),
+		Networks:     make(map[string]dto.ContainerNetwork),
+		Mounts:       make([]dto.ContainerMount, 0, len(inspect.Mounts)),
+	}
+	if inspect.ImageManifestDescriptor != nil {
+		response.ImageDigest = inspect.ImageManifestDescriptor.Digest.String()
+	}
+	if inspect.State != nil {
+		response.OOMKilled = inspect.State.OOMKilled
+	}
+	if inspect.Config != nil {
+		response.Image = inspect.Config.Image
+		response.Entrypoint = inspect.Config.Entrypoint
+		response.Command = inspect.Config.Cmd
+		response.Labels = inspect.Config.Labels
+		for port := range inspect.Config.ExposedPorts {
+			response.ExposedPorts = append(response.ExposedPorts, port.String())
+		}
+		slices.Sort(response.ExposedPorts)
+	}
+	if inspect.NetworkSettings != nil {
+		for name, endpoint := range inspect.NetworkSettings.Networks {
+			if endpoint == nil {
+				continue
+			}
+			response.Networks[name] = dto.ContainerNetwork{
+				IPAddress:   formatAddr(endpoint.IPAddress),
+				IPv6Address: formatAddr(endpoint.GlobalIPv6Address),
+				Gateway:     formatAddr(endpoint.Gateway),
+				MacAddress:  endpoint.MacAddress.String(),
+				Aliases:     endpoint.DNSNames,
+			}
+		}
+	}
+	for _, mountPoint := range inspect.Mounts {
+		response.Mounts = append(response.Mounts, dto.ContainerMount{
+			Type:        mountPoint.Type,
+			Name:        mountPoint.Name,
+			Source:      mountPoint.Source,
+			Destination: mountPoint.Destination,
+			ReadOnly:    !mountPoint.RW,
+		})
+	}
+
+	return response, nil
+}
+
+// formatAddr formats an IP address, returning an empty string for unset
+// addresses.
+func formatAddr(addr netip.Addr) string {
+	if !addr.IsValid() {
+		return ""
+	}
+	return addr.String()
+}
+
+// GetContainerLogs streams the logs of the container selected by the
+// request. The channel is closed once all logs were read, or, when
+// following, once the container stops or the context is done.
+func (s *DockerService) GetContainerLogs(
+	ctx context.Context,
+	containerID string,
+	request dto.ServiceLogsRequest,
+) (<-chan pkg.LogEntry, error) {
 	logsOptions := client.ContainerLogsOptions{
-		ShowStdout: true,
-		ShowStderr: true,
-		Timestamps: true,
-		Follow:     true,
-		Tail:       "all",
+		ShowStdout: request.Stream == "" || request.Stream == pkg.LogStreamStdout,
+		ShowStderr: request.Stream == "" || request.Stream == pkg.LogStreamStderr,
+		Timestamps: request.Timestamps,
+		Follow:     request.Follow,
+		Tail:       request.Tail,
+		Since:      request.Since,
+		Until:      request.Until,
 		Details:    true,
 	}
 	logsResult, err := s.client.ContainerLogs(ctx, containerID, logsOptions)
@@ -165,19 +531,22 @@ func (s *DockerService) GetContainerLogs(ctx context.Context, containerID string
 	}
 
 	channel := make(chan pkg.LogEntry)
-	writer := pkg.NewLogsWriter(channel)
+	stdoutWriter := pkg.NewLogsWriter(channel, pkg.LogStreamStdout, request.Timestamps)
+	stderrWriter := pkg.NewLogsWriter(channel, pkg.LogStreamStderr, request.Timestamps)
 
 	go func() {
 		defer logsResult.Close()
 		defer close(channel)
 
-		if _, err = stdcopy.StdCopy(writer, writer, logsResult); err != nil && !errors.Is(err, context.Canceled) {
+		if _, err = stdcopy.StdCopy(stdoutWriter, stderrWriter, logsResult); err != nil && !errors.Is(err, context.Canceled) {
 			log.Error().Err(err).Str("container_id", containerID).
 				Msg("error copying logs")
 		}
-		if err := writer.FlushRemaining(); err != nil && !errors.Is(err, context.Canceled) {
-			log.Error().Err(err).Str("container_id", containerID).
-				Msg("error flushing remaining logs")
+		for _, writer := range []*pkg.LogsWriter{stdoutWriter, stderrWriter} {
+			if err := writer.FlushRemaining(); err != nil && !errors.Is(err, context.Canceled) {
+				log.Error().Err(err).Str("container_id", containerID).
+					Msg("error flushing remaining logs")
+			}
 		}
 	}()
 
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/Pelfox/gidock/internal/dto"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/client"
+	"github.com/rs/zerolog/log"
+)
+
+// GetContainerStats returns a single resource usage sample of the container.
+// Docker collects a previous sample first, so the CPU usage is known.
+func (s *DockerService) GetContainerStats(ctx context.Context, containerID string) (*dto.ServiceStatsResponse, error) {
+	statsResult, err := s.client.ContainerStats(ctx, containerID, client.ContainerStatsOptions{
+		IncludePreviousSample: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer statsResult.Body.Close()
+
+	var stats container.StatsResponse
+	if err := json.NewDecoder(statsResult.Body).Decode(&stats); err != nil {
+		return nil, err
+	}
+	return newStatsResponse(stats), nil
+}
+
+// StreamContainerStats streams resource usage samples of the container
+// (roughly one per second) until the context is done or the container stops.
+func (s *DockerService) StreamContainerStats(
+	ctx context.Context,
+	containerID string,
+) (<-chan dto.ServiceStatsResponse, error) {
+	statsResult, err := s.client.ContainerStats(ctx, containerID, client.ContainerStatsOptions{Stream: true})
+	if err != nil {
+		return nil, err
+	}
+
+	channel := make(chan dto.ServiceStatsResponse)
+	go func() {
+		defer statsResult.Body.Close()
+		defer close(channel)
+
+		decoder := json.NewDecoder(statsResult.Body)
+		for {
+			var stats container.StatsResponse
+			if err := decoder.Decode(&stats); err != nil {
+				if !errors.Is(err, io.EOF) && !errors.Is(err, context.Canceled) {
+					log.Error().Err(err).Str("container_id", containerID).Msg("error decoding stats")
+				}
+				return
+			}
+
+			select {
+			case channel <- *newStatsResponse(stats):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return channel, nil
+}
+
+// newStatsResponse computes the stats response from a raw Docker sample, the
+// same way `docker stats` does.
+func newStatsResponse(stats container.StatsResponse) *dto.ServiceStatsResponse {
+	response := &dto.ServiceStatsResponse{
+		Timestamp:   stats.Read,
+		CPUPercent:  cpuPercent(stats),
+		MemoryUsage: memoryUsage(stats.MemoryStats),
+		MemoryLimit: stats.MemoryStats.Limit,
+		Pids:        stats.PidsStats.Current,
+	}
+	if response.MemoryLimit != 0 {
+		response.MemoryPercent = float64(response.MemoryUsage) / float64(response.MemoryLimit) * 100
+	}
+	for _, network := range stats.Networks {
+		response.NetworkRx += network.RxBytes
+		response.NetworkTx += network.TxBytes
+	}
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			response.BlockRead += entry.Value
+		case "write":
+			response.BlockWrite += entry.Value
+		}
+	}
+	return response
+}
+
+// cpuPercent returns the CPU usage between the previous and the current
+// sample, where 100% is one fully used CPU.
+func cpuPercent(stats container.StatsResponse) float64 {
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+
+	onlineCPUs := float64(stats.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+	return cpuDelta / systemDelta * onlineCPUs * 100
+}
+
+// memoryUsage returns the memory usage without the page cache, which the
+// kernel reclaims under memory pressure.
+func memoryUsage(stats container.MemoryStats) uint64 {
+	// cgroup v1 reports `total_inactive_file`, cgroup v2 `inactive_file`
+	cache, ok := stats.Stats["total_inactive_file"]
+	if !ok {
+		cache = stats.Stats["inactive_file"]
+	}
+	if cache > stats.Usage {
+		return 0
+	}
+	return stats.Usage - cache
+}
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Pelfox/gidock/internal"
+	"github.com/google/uuid"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/mount"
+	"github.com/moby/moby/api/types/volume"
+	"github.com/moby/moby/client"
+	"github.com/rs/zerolog/log"
+)
+
+// volumeHelperMountPath is the path the volume is mounted at inside helper
+// containers.
+const volumeHelperMountPath = "/volume"
+
+// volumeRestoreStagingDir is the directory (relative to the volume root) an
+// archive is extracted into before it replaces the volume contents.
+const volumeRestoreStagingDir = ".gidock-restore"
+
+// volumeRestoreSwapScript replaces the volume contents with the extracted
+// archive from the staging directory.
+const volumeRestoreSwapScript = `set -e
+cd ` + volumeHelperMountPath + `
+find . -mindepth 1 -maxdepth 1 ! -name ` + volumeRestoreStagingDir + ` -exec rm -rf {} \;
+find ` + volumeRestoreStagingDir + ` -mindepth 1 -maxdepth 1 -exec mv {} . \;
+rmdir ` + volumeRestoreStagingDir
+
+// CreateProjectVolume creates a named volume labelled as belonging to the
+// given project.
+func (s *DockerService) CreateProjectVolume(
+	ctx context.Context,
+	projectID uuid.UUID,
+	name string,
+	driver string,
+) (*volume.Volume, error) {
+	createResult, err := s.client.VolumeCreate(ctx, client.VolumeCreateOptions{
+		Name:   name,
+		Driver: driver,
+		Labels: map[string]string{
+			"gidock.volume":     "true",
+			"gidock.project_id": projectID.String(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &createResult.Volume, nil
+}
+
+// ListProjectVolumes returns all volumes labelled as belonging to the given
+// project, including their usage data.
+func (s *DockerService) ListProjectVolumes(ctx context.Context, projectID uuid.UUID) ([]volume.Volume, error) {
+	// disk usage is the only endpoint reporting volume sizes, but it can't be filtered
+	diskUsage, err := s.client.DiskUsage(ctx, client.DiskUsageOptions{Volumes: true, Verbose: true})
+	if err != nil {
+		return nil, err
+	}
+
+	volumes := make([]volume.Volume, 0)
+	for _, item := range diskUsage.Volumes.Items {
+		if item.Labels["gidock.project_id"] == projectID.String() {
+			volumes = append(volumes, item)
+		}
+	}
+	return volumes, nil
+}
+
+// InspectVolume returns the volume with the given name.
+func (s *DockerService) InspectVolume(ctx context.Context, name string) (*volume.Volume, error) {
+	inspectResult, err := s.client.VolumeInspect(ctx, name, client.VolumeInspectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &inspectResult.Volume, nil
+}
+
+// RemoveVolume removes the volume with the given name. Docker refuses to
+// remove volumes used by any container.
+func (s *DockerService) RemoveVolume(ctx context.Context, name string) error {
+	_, err := s.client.VolumeRemove(ctx, name, client.VolumeRemoveOptions{})
+	return err
+}
+
+// ExportVolume returns a gzip-compressed tar archive of the volume contents.
+// The archive is read from a short-lived helper container created from
+// `helperImage`, which is removed when the returned reader is closed.
+func (s *DockerService) ExportVolume(ctx context.Context, name string, helperImage string) (io.ReadCloser, error) {
+	helperID, err := s.createVolumeHelper(ctx, name, helperImage, true, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	copyResult, err := s.client.CopyFromContainer(ctx, helperID, client.CopyFromContainerOptions{
+		SourcePath: volumeHelperMountPath + "/.",
+	})
+	if err != nil {
+		s.removeVolumeHelper(ctx, helperID)
+		return nil, err
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		defer copyResult.Content.Close()
+
+		gzipWriter := gzip.NewWriter(writer)
+		_, err := io.Copy(gzipWriter, copyResult.Content)
+		if err == nil {
+			err = gzipWriter.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	return &cleanupReadCloser{
+		ReadCloser: reader,
+		cleanup: func() {
+			s.removeVolumeHelper(ctx, helperID)
+		},
+	}, nil
+}
+
+// ImportVolume replaces the volume contents with the given gzip-compressed
+// tar archive, using short-lived helper containers created from
+// `helperImage`. The archive is extracted into a staging directory first, so
+// the current contents are only replaced once it was extracted completely.
+func (s *DockerService) ImportVolume(
+	ctx context.Context,
+	name string,
+	helperImage string,
+	archive io.Reader,
+) error {
+	gzipReader, err := gzip.NewReader(archive)
+	if err != nil {
+		return fmt.Errorf("%w: %w", internal.ErrInvalidArchive, err)
+	}
+	defer gzipReader.Close()
+
+	// leftovers of a previously failed restore must not end up in the volume
+	if err := s.discardRestoreStaging(ctx, name, helperImage); err != nil {
+		return fmt.Errorf("failed to prepare volume: %w", err)
+	}
+
+	helperID, err := s.createVolumeHelper(
+		ctx,
+		name,
+		helperImage,
+		false,
+		[]string{"sh", "-c", volumeRestoreSwapScript},
+	)
+	if err != nil {
+		return err
+	}
+	defer s.removeVolumeHelper(ctx, helperID)
+
+	if err := s.copyToRestoreStaging(ctx, helperID, gzipReader); err != nil {
+		if discardErr := s.discardRestoreStaging(context.WithoutCancel(ctx), name, helperImage); discardErr != nil {
+			log.Error().Err(discardErr).Str("volume", name).
+				Msg("failed to discard staged volume contents")
+		}
+		return err
+	}
+
+	// the swap must not be interrupted once it started
+	if err := s.runToCompletion(context.WithoutCancel(ctx), helperID); err != nil {
+		return fmt.Errorf("failed to replace volume contents: %w", err)
+	}
+	return nil
+}
+
+// copyToRestoreStaging extracts the tar archive into the staging directory of
+// the volume mounted by the helper container. Read errors of the archive are
+// reported as `internal.ErrInvalidArchive`.
+func (s *DockerService) copyToRestoreStaging(ctx context.Context, helperID string, archive io.Reader) error {
+	reader, writer := io.Pipe()
+	stageResult := make(chan error, 1)
+	go func() {
+		err := stageArchive(archive, writer)
+		writer.CloseWithError(err)
+		stageResult <- err
+	}()
+
+	_, err := s.client.CopyToContainer(ctx, helperID, client.CopyToContainerOptions{
+		DestinationPath: volumeHelperMountPath,
+		Content:         reader,
+	})
+	// unblocks the staging goroutine if Docker stopped reading early
+	reader.CloseWithError(io.ErrClosedPipe)
+
+	// an invalid archive is the actual cause of the failed copy
+	if stageErr := <-stageResult; errors.Is(stageErr, internal.ErrInvalidArchive) {
+		return stageErr
+	}
+	return err
+}
+
+// discardRestoreStaging removes the staging directory from the volume.
+func (s *DockerService) discardRestoreStaging(ctx context.Context, name string, helperImage string) error {
+	helperID, err := s.createVolumeHelper(
+		ctx,
+		name,
+		helperImage,
+		false,
+		[]string{"rm", "-rf", path.Join(volumeHelperMountPath, volumeRestoreStagingDir)},
+	)
+	if err != nil {
+		return err
+	}
+	defer s.removeVolumeHelper(ctx, helperID)
+	return s.runToCompletion(ctx, helperID)
+}
+
+// stageArchive copies the tar archive to the writer, moving every entry into
+// `volumeRestoreStagingDir`. The archive is read completely, so truncated or
+// corrupted archives are detected.
+func stageArchive(archive io.Reader, writer io.Writer) error {
+	tarReader := tar.NewReader(archive)
+	tarWriter := tar.NewWriter(writer)
+
+	err := tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     volumeRestoreStagingDir + "/",
+		Mode:     0o755,
+		ModTime:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", internal.ErrInvalidArchive, err)
+		}
+
+		var ok bool
+		if header.Name, ok = stagedArchivePath(header.Name); !ok {
+			return fmt.Errorf("%w: entry %q points outside of the volume", internal.ErrInvalidArchive, header.Name)
+		}
+		// hard link targets are paths within the archive as well
+		if header.Typeflag == tar.TypeLink {
+			if header.Linkname, ok = stagedArchivePath(header.Linkname); !ok {
+				return fmt.Errorf("%w: link %q points outside of the volume", internal.ErrInvalidArchive, header.Linkname)
+			}
+		}
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tarWriter, tarReader); err != nil {
+			if errors.Is(err, io.ErrClosedPipe) {
+				return err
+			}
+			return fmt.Errorf("%w: %w", internal.ErrInvalidArchive, err)
+		}
+	}
+
+	// the gzip checksum is only verified once the stream is read to its end
+	if _, err := io.Copy(io.Discard, archive); err != nil {
+		return fmt.Errorf("%w: %w", internal.ErrInvalidArchive, err)
+	}
+	return tarWriter.Close()
+}
+
+// stagedArchivePath returns the path of the archive entry within the staging
+// directory. It reports false if the entry would escape the directory.
+func stagedArchivePath(name string) (string, bool) {
+	cleaned := path.Clean(strings.TrimLeft(name, "/"))
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", false
+	}
+	return path.Join(volumeRestoreStagingDir, cleaned), true
+}
+
+// createVolumeHelper creates (without starting) a helper container with the
+// volume mounted at `volumeHelperMountPath`.
+func (s *DockerService) createVolumeHelper(
+	ctx context.Context,
+	name string,
+	helperImage string,
+	readOnly bool,
+	command []string,
+) (string, error) {
+	if err := s.pullImage(ctx, helperImage, log.Logger); err != nil {
+		return "", err
+	}
+
+	createResult, err := s.client.ContainerCreate(ctx, client.ContainerCreateOptions{
+		Config: &container.Config{
+			Cmd:             command,
+			NetworkDisabled: true,
+			Labels: map[string]string{
+				"gidock.helper": "true",
+				"gidock.volume": name,
+			},
+		},
+		HostConfig: &container.HostConfig{
+			Mounts: []mount.Mount{{
+				Type:     mount.TypeVolume,
+				Source:   name,
+				Target:   volumeHelperMountPath,
+				ReadOnly: readOnly,
+			}},
+		},
+		Image: helperImage,
+	})
+	if err != nil {
+		return "", err
+	}
+	return createResult.ID, nil
+}
+
+// runToCompletion starts the container and waits for it to exit, returning an
+// error if it exits with a non-zero code.
+func (s *DockerService) runToCompletion(ctx context.Context, containerID string) error {
+	waitResult := s.client.ContainerWait(ctx, containerID, client.ContainerWaitOptions{
+		Condition: container.WaitConditionNextExit,
+	})
+	if _, err := s.client.ContainerStart(ctx, containerID, client.ContainerStartOptions{}); err != nil {
+		return err
+	}
+
+	select {
+	case result := <-waitResult.Result:
+		if result.Error != nil {
+			return fmt.Errorf("%w: %s", internal.ErrHelperFailed, result.Error.Message)
+		}
+		if result.StatusCode != 0 {
+			return fmt.Errorf("%w with code %d", internal.ErrHelperFailed, result.StatusCode)
+		}
+		return nil
+	case err := <-waitResult.Error:
+		return err
+	}
+}
+
+// removeVolumeHelper removes the helper container, logging any failure. It
+// also runs when the request context is already canceled.
+func (s *DockerService) removeVolumeHelper(ctx context.Context, helperID string) {
+	if err := s.RemoveContainer(context.WithoutCancel(ctx), helperID, false); err != nil {
+		log.Error().Err(err).Str("container_id", helperID).
+			Msg("failed to remove volume helper container")
+	}
+}
+
+// cleanupReadCloser is an `io.ReadCloser` running a cleanup function after
+// the underlying reader is closed.
+type cleanupReadCloser struct {
+	io.ReadCloser
+	cleanup func()
+}
+
+func (r *cleanupReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.cleanup()
+	return err
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/Pelfox/gidock/internal"
+)
+
+// buildArchive returns a gzip-compressed tar archive with the given entries.
+func buildArchive(t *testing.T, headers ...*tar.Header) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, header := range headers {
+		if header.Typeflag == tar.TypeReg {
+			header.Size = int64(len(header.Name))
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := tarWriter.Write([]byte(header.Name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestStageArchive(t *testing.T) {
+	valid := buildArchive(t,
+		&tar.Header{Typeflag: tar.TypeDir, Name: "./", Mode: 0o755},
+		&tar.Header{Typeflag: tar.TypeDir, Name: "./data/", Mode: 0o755},
+		&tar.Header{Typeflag: tar.TypeReg, Name: "./data/file", Mode: 0o644},
+		&tar.Header{Typeflag: tar.TypeLink, Name: "./data/link", Linkname: "./data/file"},
+		&tar.Header{Typeflag: tar.TypeSymlink, Name: "./data/symlink", Linkname: "../other"},
+	)
+	corrupted := slices.Clone(valid)
+	corrupted[len(corrupted)-5] ^= 0xff
+
+	tests := []struct {
+		name    string
+		archive []byte
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "valid archive",
+			archive: valid,
+			want: []string{
+				".gidock-restore/",
+				".gidock-restore",
+				".gidock-restore/data",
+				".gidock-restore/data/file",
+				".gidock-restore/data/link -> .gidock-restore/data/file",
+				".gidock-restore/data/symlink -> ../other",
+			},
+		},
+		{name: "truncated archive", archive: valid[:len(valid)/2], wantErr: true},
+		{name: "corrupted checksum", archive: corrupted, wantErr: true},
+		{
+			name: "entry outside of the volume",
+			archive: buildArchive(t,
+				&tar.Header{Typeflag: tar.TypeReg, Name: "../escape", Mode: 0o644},
+			),
+			wantErr: true,
+		},
+		{
+			name: "hard link outside of the volume",
+			archive: buildArchive(t,
+				&tar.Header{Typeflag: tar.TypeLink, Name: "link", Linkname: "../../etc/passwd"},
+			),
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gzipReader, err := gzip.NewReader(bytes.NewReader(test.archive))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var staged bytes.Buffer
+			err = stageArchive(gzipReader, &staged)
+			if test.wantErr {
+				if !errors.Is(err, internal.ErrInvalidArchive) {
+					t.Fatalf("stageArchive() error = %v, want %v", err, internal.ErrInvalidArchive)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("stageArchive() error = %v", err)
+			}
+
+			var got []string
+			tarReader := tar.NewReader(&staged)
+			for {
+				header, err := tarReader.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				entry := header.Name
+				if header.Linkname != "" {
+					entry += " -> " + header.Linkname
+				}
+				got = append(got, entry)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("stageArchive() entries = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Pelfox/gidock/internal/models"
+	"github.com/Pelfox/gidock/internal/repositories/commands"
+)
+
+// portKey identifies a published container port of a service.
+type portKey struct {
+	containerPort int
+	protocol      string
+}
+
+// allocateHostPorts returns a copy of the service whose published ports
+// without an explicit host port use the host port allocated to them. Ports
+// are allocated on first use and reused afterwards; allocations of ports the
+// service no longer publishes are released.
+func (s *ServiceService) allocateHostPorts(ctx context.Context, service *models.Service) (*models.Service, error) {
+	allocations, err := s.portAllocationRepository.ListByService(
+		ctx,
+		commands.ListServicePortAllocationsCommand{ServiceID: service.ID},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	allocated := make(map[portKey]models.PortAllocation, len(allocations))
+	for _, allocation := range allocations {
+		allocated[portKey{allocation.ContainerPort, allocation.Protocol}] = allocation
+	}
+
+	deployedService := *service
+	deployedService.Ports = make([]models.ServicePort, len(service.Ports))
+	used := make(map[portKey]bool, len(service.Ports))
+	for i, servicePort := range service.Ports {
+		deployedService.Ports[i] = servicePort
+		if servicePort.HostPort != 0 {
+			continue
+		}
+
+		key := portKey{servicePort.ContainerPort, servicePort.PortProtocol()}
+		used[key] = true
+		allocation, ok := allocated[key]
+		if !ok {
+			newAllocation, err := s.portAllocationRepository.Allocate(ctx, commands.AllocatePortCommand{
+				ServiceID:     service.ID,
+				ContainerPort: servicePort.ContainerPort,
+				Protocol:      key.protocol,
+				RangeStart:    s.config.PortRangeStart,
+				RangeEnd:      s.config.PortRangeEnd,
+			})
+			if err != nil {
+				return nil, err
+			}
+			allocation = *newAllocation
+		}
+		deployedService.Ports[i].HostPort = allocation.HostPort
+	}
+
+	for key, allocation := range allocated {
+		if used[key] {
+			continue
+		}
+		err := s.portAllocationRepository.Release(ctx, commands.ReleasePortAllocationCommand{
+			ServiceID: service.ID,
+			HostPort:  allocation.HostPort,
+			Protocol:  allocation.Protocol,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &deployedService, nil
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Pelfox/gidock/internal"
+	"github.com/Pelfox/gidock/internal/dto"
+	"github.com/Pelfox/gidock/internal/models"
+	"github.com/Pelfox/gidock/internal/repositories/commands"
+	"github.com/google/uuid"
+)
+
+const (
+	// logsReorderWindow is how long merged log entries are held back, so
+	// that entries of different services arriving slightly out of order can
+	// still be emitted in timestamp order.
+	logsReorderWindow = 500 * time.Millisecond
+	// maxBufferedLogEntries bounds the number of held back log entries (e.g.
+	// while the tail of every service is read at once).
+	maxBufferedLogEntries = 10000
+)
+
+// StreamLogs follows the logs of the project services concurrently and
+// merges them into a single stream ordered by timestamp. If `serviceIDs` is
+// not empty, only the logs of these services are followed. The request is
+// always followed and timestamped.
+func (s *ProjectService) StreamLogs(
+	ctx context.Context,
+	id uuid.UUID,
+	serviceIDs []uuid.UUID,
+	request dto.ServiceLogsRequest,
+) (<-chan dto.ProjectLogEntry, error) {
+	_, err := s.projectRepository.Get(ctx, commands.GetProjectCommand{ID: id})
+	if err != nil {
+		return nil, err
+	}
+
+	projectServices, err := s.serviceRepository.ListByProject(
+		ctx,
+		commands.ListProjectServicesCommand{ProjectID: id},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := selectLogServices(projectServices, serviceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Follow = true
+	request.Timestamps = true
+
+	ctx, cancel := context.WithCancel(ctx)
+	merged := make(chan dto.ProjectLogEntry)
+	var wg sync.WaitGroup
+
+	for _, service := range selected {
+		logsChannel, err := s.dockerService.GetContainerLogs(ctx, *service.ContainerID, request)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to get logs of service %q (%s): %w", service.Name, service.ID, err)
+		}
+
+		wg.Go(func() {
+			// keep draining after the context is done, so that the log
+			// reader can finish
+			for entry := range logsChannel {
+				select {
+				case merged <- dto.ProjectLogEntry{ServiceID: service.ID, Name: service.Name, LogEntry: entry}:
+				case <-ctx.Done():
+				}
+			}
+		})
+	}
+
+	go func() {
+		wg.Wait()
+		close(merged)
+	}()
+
+	channel := make(chan dto.ProjectLogEntry)
+	go func() {
+		defer cancel()
+		defer close(channel)
+		reorderLogEntries(ctx, merged, channel)
+	}()
+
+	return channel, nil
+}
+
+// selectLogServices returns the services whose logs should be followed: the
+// given services or every project service if none were given. Services
+// without a container are skipped, unless they were explicitly requested.
+func selectLogServices(projectServices []models.Service, serviceIDs []uuid.UUID) ([]models.Service, error) {
+	if len(serviceIDs) == 0 {
+		selected := make([]models.Service, 0, len(projectServices))
+		for _, service := range projectServices {
+			if service.ContainerID != nil {
+				selected = append(selected, service)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, internal.ErrNoContainer
+		}
+		return selected, nil
+	}
+
+	byID := make(map[uuid.UUID]models.Service, len(projectServices))
+	for _, service := range projectServices {
+		byID[service.ID] = service
+	}
+
+	selected := make([]models.Service, 0, len(serviceIDs))
+	seen := make(map[uuid.UUID]bool, len(serviceIDs))
+	for _, serviceID := range serviceIDs {
+		service, ok := byID[serviceID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", internal.ErrServiceNotInProject, serviceID)
+		}
+		if service.ContainerID == nil {
+			return nil, fmt.Errorf("%w: %q (%s)", internal.ErrNoContainer, service.Name, service.ID)
+		}
+		if !seen[serviceID] {
+			seen[serviceID] = true
+			selected = append(selected, service)
+		}
+	}
+	return selected, nil
+}
+
+// reorderLogEntries forwards the entries from `input` to `output` in
+// timestamp order. Every entry is held back for `logsReorderWindow` after it
+// was received, unless the buffer is full. The remaining entries are flushed
+// once `input` is closed.
+func reorderLogEntries(ctx context.Context, input <-chan dto.ProjectLogEntry, output chan<- dto.ProjectLogEntry) {
+	buffer := &logEntryHeap{}
+	ticker := time.NewTicker(logsReorderWindow / 5)
+	defer ticker.Stop()
+
+	// emit sends the oldest buffered entry and reports whether it was sent
+	emit := func() bool {
+		entry := heap.Pop(buffer).(bufferedLogEntry)
+		select {
+		case output <- entry.entry:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for {
+		select {
+		case entry, ok := <-input:
+			if !ok {
+				for buffer.Len() > 0 {
+					if !emit() {
+						return
+					}
+				}
+				return
+			}
+			heap.Push(buffer, bufferedLogEntry{entry: entry, receivedAt: time.Now()})
+			for buffer.Len() > maxBufferedLogEntries {
+				if !emit() {
+					return
+				}
+			}
+		case now := <-ticker.C:
+			for buffer.Len() > 0 && now.Sub((*buffer)[0].receivedAt) >= logsReorderWindow {
+				if !emit() {
+					return
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// bufferedLogEntry is a log entry held back for reordering.
+type bufferedLogEntry struct {
+	entry      dto.ProjectLogEntry
+	receivedAt time.Time
+}
+
+// logEntryHeap is a min-heap of log entries ordered by their timestamp.
+type logEntryHeap []bufferedLogEntry
+
+func (h logEntryHeap) Len() int {
+	return len(h)
+}
+
+func (h logEntryHeap) Less(i, j int) bool {
+	return h[i].entry.Timestamp.Before(h[j].entry.Timestamp)
+}
+
+func (h logEntryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *logEntryHeap) Push(x any) {
+	*h = append(*h, x.(bufferedLogEntry))
+}
+
+func (h *logEntryHeap) Pop() any {
+	old := *h
+	entry := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return entry
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"container/heap"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Pelfox/gidock/internal/dto"
+	"github.com/Pelfox/gidock/pkg"
+)
+
+// testLogEntry returns a project log entry with the content and the given
+// offset from a fixed timestamp.
+func testLogEntry(content string, offset time.Duration) dto.ProjectLogEntry {
+	timestamp := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Add(offset)
+	return dto.ProjectLogEntry{LogEntry: pkg.LogEntry{Timestamp: timestamp, Content: content}}
+}
+
+func TestReorderLogEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []dto.ProjectLogEntry
+		want    []string
+	}{
+		{name: "no entries"},
+		{
+			name: "ordered",
+			entries: []dto.ProjectLogEntry{
+				testLogEntry("a", 0), testLogEntry("b", time.Millisecond), testLogEntry("c", 2*time.Millisecond),
+			},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "out of order",
+			entries: []dto.ProjectLogEntry{
+				testLogEntry("c", 2*time.Millisecond), testLogEntry("a", 0), testLogEntry("b", time.Millisecond),
+			},
+			want: []string{"a", "b", "c"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input := make(chan dto.ProjectLogEntry, len(test.entries))
+			for _, entry := range test.entries {
+				input <- entry
+			}
+			close(input)
+
+			output := make(chan dto.ProjectLogEntry, len(test.entries))
+			reorderLogEntries(context.Background(), input, output)
+			close(output)
+
+			var got []string
+			for entry := range output {
+				got = append(got, entry.Content)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("entries = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReorderLogEntriesWindow(t *testing.T) {
+	input := make(chan dto.ProjectLogEntry)
+	output := make(chan dto.ProjectLogEntry)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reorderLogEntries(ctx, input, output)
+
+	// entries are emitted after the window, while the input is still open
+	input <- testLogEntry("b", time.Millisecond)
+	input <- testLogEntry("a", 0)
+	for _, want := range []string{"a", "b"} {
+		select {
+		case entry := <-output:
+			if entry.Content != want {
+				t.Errorf("entry = %q, want %q", entry.Content, want)
+			}
+		case <-time.After(4 * logsReorderWindow):
+			t.Fatalf("entry %q wasn't emitted after the reorder window", want)
+		}
+	}
+}
+
+func TestLogEntryHeap(t *testing.T) {
+	buffer := &logEntryHeap{}
+	for i, offset := range []time.Duration{3, 1, 4, 1, 5, 9, 2, 6} {
+		entry := testLogEntry(string(rune('a'+i)), offset*time.Millisecond)
+		heap.Push(buffer, bufferedLogEntry{entry: entry})
+	}
+
+	var previous time.Time
+	for buffer.Len() > 0 {
+		entry := heap.Pop(buffer).(bufferedLogEntry).entry
+		if entry.Timestamp.Before(previous) {
+			t.Fatalf("popped %s after %s", entry.Timestamp, previous)
+		}
+		previous = entry.Timestamp
+	}
+}
@@ -2,20 +2,39 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
 
+	"github.com/Pelfox/gidock/internal"
 	"github.com/Pelfox/gidock/internal/dto"
 	"github.com/Pelfox/gidock/internal/models"
 	"github.com/Pelfox/gidock/internal/repositories"
 	"github.com/Pelfox/gidock/internal/repositories/commands"
 	"github.com/google/uuid"
+	"github.com/moby/moby/api/types/container"
 )
 
 type ProjectService struct {
 	projectRepository *repositories.ProjectRepository
+	serviceRepository *repositories.ServiceRepository
+	serviceService    *ServiceService
+	dockerService     *DockerService
 }
 
-func NewProjectService(projectRepository *repositories.ProjectRepository) *ProjectService {
-	return &ProjectService{projectRepository: projectRepository}
+func NewProjectService(
+	projectRepository *repositories.ProjectRepository,
+	serviceRepository *repositories.ServiceRepository,
+	serviceService *ServiceService,
+	dockerService *DockerService,
+) *ProjectService {
+	return &ProjectService{
+		projectRepository: projectRepository,
+		serviceRepository: serviceRepository,
+		serviceService:    serviceService,
+		dockerService:     dockerService,
+	}
 }
 
 func (s *ProjectService) Create(
@@ -49,7 +68,30 @@ func (s *ProjectService) Update(
 	})
 }
 
-func (s *ProjectService) Delete(ctx context.Context, id uuid.UUID) error {
+// Delete stops and removes the containers of every project service in reverse
+// dependency order (along with their anonymous volumes if `removeVolumes` is
+// set), removes the project network and deletes the project with all of its
+// services.
+func (s *ProjectService) Delete(ctx context.Context, id uuid.UUID, removeVolumes bool) error {
+	order, err := s.resolveServiceOrder(ctx, id)
+	// a broken dependency graph must not prevent the project from being deleted
+	if errors.Is(err, internal.ErrDependencyCycle) || errors.Is(err, internal.ErrInvalidDependency) {
+		order, err = s.listServicesUnordered(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range slices.Backward(order) {
+		if err := s.serviceService.removeContainer(ctx, &entry.Service, removeVolumes); err != nil {
+			return fmt.Errorf("failed to remove container of service %q (%s): %w", entry.Service.Name, entry.Service.ID, err)
+		}
+	}
+
+	if err := s.dockerService.RemoveProjectNetwork(ctx, id); err != nil {
+		return fmt.Errorf("failed to remove project network: %w", err)
+	}
+
 	return s.projectRepository.Delete(ctx, commands.DeleteProjectCommand{ID: id})
 }
 
@@ -60,3 +102,233 @@ func (s *ProjectService) ListAll(ctx context.Context) ([]models.Project, error)
 	}
 	return projects, nil
 }
+
+// Start starts every service of the project in dependency order. Services
+// whose dependencies failed to start are not started. The result of every
+// service is reported individually.
+func (s *ProjectService) Start(ctx context.Context, id uuid.UUID) (*dto.ProjectOperationResponse, error) {
+	order, err := s.resolveServiceOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.startServices(ctx, order), nil
+}
+
+// Stop stops every service of the project in reverse dependency order.
+func (s *ProjectService) Stop(ctx context.Context, id uuid.UUID, kill bool) (*dto.ProjectOperationResponse, error) {
+	order, err := s.resolveServiceOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.stopServices(ctx, order, kill), nil
+}
+
+// Restart stops every service of the project in reverse dependency order and
+// then starts them again in dependency order.
+func (s *ProjectService) Restart(ctx context.Context, id uuid.UUID, kill bool) (*dto.ProjectOperationResponse, error) {
+	order, err := s.resolveServiceOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stopResponse := s.stopServices(ctx, order, kill)
+	startResponse := s.startServices(ctx, order)
+
+	// a failed stop takes precedence over the start result of the same service
+	stopFailures := make(map[uuid.UUID]dto.ServiceOperationResult)
+	for _, result := range stopResponse.Services {
+		if !result.Success {
+			stopFailures[result.ServiceID] = result
+		}
+	}
+
+	response := &dto.ProjectOperationResponse{
+		Success:  stopResponse.Success && startResponse.Success,
+		Services: make([]dto.ServiceOperationResult, 0, len(startResponse.Services)),
+	}
+	for _, result := range startResponse.Services {
+		if stopFailure, ok := stopFailures[result.ServiceID]; ok {
+			result = stopFailure
+		}
+		response.Services = append(response.Services, result)
+	}
+	return response, nil
+}
+
+// resolveServiceOrder ensures the project exists and returns its services in
+// dependency order.
+func (s *ProjectService) resolveServiceOrder(ctx context.Context, id uuid.UUID) ([]resolvedDependency, error) {
+	_, err := s.projectRepository.Get(ctx, commands.GetProjectCommand{ID: id})
+	if err != nil {
+		return nil, err
+	}
+	return s.serviceService.resolveProjectOrder(ctx, id)
+}
+
+// listServicesUnordered returns the services of the project without
+// resolving their dependency order.
+func (s *ProjectService) listServicesUnordered(ctx context.Context, id uuid.UUID) ([]resolvedDependency, error) {
+	projectServices, err := s.serviceRepository.ListByProject(
+		ctx,
+		commands.ListProjectServicesCommand{ProjectID: id},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]resolvedDependency, len(projectServices))
+	for i, service := range projectServices {
+		result[i] = resolvedDependency{Service: service}
+	}
+	return result, nil
+}
+
+// startServices starts the given services in order, skipping services with
+// failed dependencies.
+func (s *ProjectService) startServices(ctx context.Context, order []resolvedDependency) *dto.ProjectOperationResponse {
+	response := &dto.ProjectOperationResponse{
+		Success:  true,
+		Services: make([]dto.ServiceOperationResult, 0, len(order)),
+	}
+	failed := make(map[uuid.UUID]string)
+
+	for _, entry := range order {
+		result := dto.ServiceOperationResult{
+			ServiceID: entry.Service.ID,
+			Name:      entry.Service.Name,
+			Success:   true,
+		}
+		if err := s.startService(ctx, entry, failed); err != nil {
+			failed[entry.Service.ID] = entry.Service.Name
+			result.Success = false
+			result.Error = err.Error()
+			response.Success = false
+		}
+		response.Services = append(response.Services, result)
+	}
+
+	return response
+}
+
+// startService starts a single service of the project. If other services
+// depend on it, it also waits until the service satisfies their condition.
+func (s *ProjectService) startService(
+	ctx context.Context,
+	entry resolvedDependency,
+	failed map[uuid.UUID]string,
+) error {
+	for _, dependency := range entry.Service.Dependencies {
+		if name, ok := failed[dependency.ServiceID]; ok {
+			return fmt.Errorf("%w: %q failed to start", internal.ErrDependencyNotReady, name)
+		}
+	}
+
+	startedService, err := s.serviceService.startContainer(ctx, &entry.Service, false, true)
+	if err != nil {
+		return err
+	}
+
+	// no other service depends on this one, so there is nothing to wait for
+	if entry.Condition == "" {
+		return nil
+	}
+	return s.serviceService.waitForCondition(ctx, startedService, entry.Condition)
+}
+
+// stopServices stops the given services in reverse order. Services that were
+// never deployed are reported as successfully stopped.
+func (s *ProjectService) stopServices(
+	ctx context.Context,
+	order []resolvedDependency,
+	kill bool,
+) *dto.ProjectOperationResponse {
+	response := &dto.ProjectOperationResponse{
+		Success:  true,
+		Services: make([]dto.ServiceOperationResult, 0, len(order)),
+	}
+
+	for _, entry := range slices.Backward(order) {
+		result := dto.ServiceOperationResult{
+			ServiceID: entry.Service.ID,
+			Name:      entry.Service.Name,
+			Success:   true,
+		}
+		if entry.Service.ContainerID != nil {
+			if err := s.dockerService.StopContainer(ctx, *entry.Service.ContainerID, kill); err != nil {
+				result.Success = false
+				result.Error = err.Error()
+				response.Success = false
+			}
+		}
+		response.Services = append(response.Services, result)
+	}
+
+	return response
+}
+
+// GetStatus inspects the containers of every service of the project in
+// parallel and returns their status along with the rolled-up project state.
+func (s *ProjectService) GetStatus(ctx context.Context, id uuid.UUID) (*dto.ProjectStatusResponse, error) {
+	_, err := s.projectRepository.Get(ctx, commands.GetProjectCommand{ID: id})
+	if err != nil {
+		return nil, err
+	}
+
+	projectServices, err := s.serviceRepository.ListByProject(
+		ctx,
+		commands.ListProjectServicesCommand{ProjectID: id},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]dto.ProjectServiceStatus, len(projectServices))
+	var wg sync.WaitGroup
+	for i, service := range projectServices {
+		statuses[i] = dto.ProjectServiceStatus{ServiceID: service.ID, Name: service.Name}
+		if service.ContainerID == nil {
+			statuses[i].Error = internal.ErrNoContainer.Error()
+			continue
+		}
+
+		wg.Go(func() {
+			status, err := s.serviceService.containerStatus(ctx, *service.ContainerID)
+			if err != nil {
+				statuses[i].Error = err.Error()
+				return
+			}
+			statuses[i].Status = status
+		})
+	}
+	wg.Wait()
+
+	return &dto.ProjectStatusResponse{
+		State:    rollUpProjectState(projectServices, statuses),
+		Services: statuses,
+	}, nil
+}
+
+// rollUpProjectState derives the overall project state from the status of
+// its services. Crash looping services don't count as running.
+func rollUpProjectState(projectServices []models.Service, statuses []dto.ProjectServiceStatus) dto.ProjectState {
+	deployed, running := 0, 0
+	for i, service := range projectServices {
+		if service.ContainerID != nil {
+			deployed++
+		}
+		if statuses[i].Status != nil && statuses[i].Status.State == container.StateRunning {
+			running++
+		}
+	}
+
+	switch {
+	case deployed == 0:
+		return dto.ProjectStateNeverDeployed
+	case running == len(projectServices):
+		return dto.ProjectStateRunning
+	case running == 0:
+		return dto.ProjectStateStopped
+	default:
+		return dto.ProjectStateDegraded
+	}
+}
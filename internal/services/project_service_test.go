@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Pelfox/gidock/internal/dto"
+	"github.com/Pelfox/gidock/internal/models"
+	"github.com/moby/moby/api/types/container"
+)
+
+func TestRollUpProjectState(t *testing.T) {
+	containerID := "container"
+	running := &dto.ServiceStatusResponse{State: container.StateRunning}
+	exited := &dto.ServiceStatusResponse{State: container.StateExited}
+	crashLooping := &dto.ServiceStatusResponse{State: dto.ServiceStateCrashLooping}
+
+	tests := []struct {
+		name     string
+		deployed []bool
+		statuses []*dto.ServiceStatusResponse
+		want     dto.ProjectState
+	}{
+		{name: "no services", want: dto.ProjectStateNeverDeployed},
+		{
+			name:     "nothing deployed",
+			deployed: []bool{false, false},
+			statuses: []*dto.ServiceStatusResponse{nil, nil},
+			want:     dto.ProjectStateNeverDeployed,
+		},
+		{
+			name:     "all running",
+			deployed: []bool{true, true},
+			statuses: []*dto.ServiceStatusResponse{running, running},
+			want:     dto.ProjectStateRunning,
+		},
+		{
+			name:     "some running",
+			deployed: []bool{true, true},
+			statuses: []*dto.ServiceStatusResponse{running, exited},
+			want:     dto.ProjectStateDegraded,
+		},
+		{
+			name:     "running and never deployed",
+			deployed: []bool{true, false},
+			statuses: []*dto.ServiceStatusResponse{running, nil},
+			want:     dto.ProjectStateDegraded,
+		},
+		{
+			name:     "none running",
+			deployed: []bool{true, true},
+			statuses: []*dto.ServiceStatusResponse{exited, nil},
+			want:     dto.ProjectStateStopped,
+		},
+		{
+			name:     "crash looping counts as not running",
+			deployed: []bool{true, true},
+			statuses: []*dto.ServiceStatusResponse{running, crashLooping},
+			want:     dto.ProjectStateDegraded,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			projectServices := make([]models.Service, len(test.deployed))
+			statuses := make([]dto.ProjectServiceStatus, len(test.deployed))
+			for i, deployed := range test.deployed {
+				if deployed {
+					projectServices[i].ContainerID = &containerID
+				}
+				statuses[i].Status = test.statuses[i]
+			}
+
+			if got := rollUpProjectState(projectServices, statuses); got != test.want {
+				t.Errorf("rollUpProjectState() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
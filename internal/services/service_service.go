@@ -2,6 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
 
 	"github.com/Pelfox/gidock/internal"
 	"github.com/Pelfox/gidock/internal/dto"
@@ -9,23 +13,37 @@ import (
 	"github.com/Pelfox/gidock/internal/repositories"
 	"github.com/Pelfox/gidock/internal/repositories/commands"
 	"github.com/Pelfox/gidock/pkg"
+	"github.com/containerd/errdefs"
 	"github.com/google/uuid"
+	"github.com/moby/moby/api/types/container"
+	"github.com/rs/zerolog/log"
 )
 
-// TODO: add other methods (from Repository)
+// batchStatusWorkers is the maximum number of containers inspected
+// concurrently by `ServiceService.GetBatchStatus`.
+const batchStatusWorkers = 8
 
 type ServiceService struct {
-	serviceRepository *repositories.ServiceRepository
-	dockerService     *DockerService
+	serviceRepository        *repositories.ServiceRepository
+	portAllocationRepository *repositories.PortAllocationRepository
+	dockerService            *DockerService
+	crashLoopDetector        *CrashLoopDetector
+	config                   *internal.AppConfig
 }
 
 func NewServiceService(
 	serviceRepository *repositories.ServiceRepository,
+	portAllocationRepository *repositories.PortAllocationRepository,
 	dockerService *DockerService,
+	crashLoopDetector *CrashLoopDetector,
+	config *internal.AppConfig,
 ) *ServiceService {
 	return &ServiceService{
-		serviceRepository: serviceRepository,
-		dockerService:     dockerService,
+		serviceRepository:        serviceRepository,
+		portAllocationRepository: portAllocationRepository,
+		dockerService:            dockerService,
+		crashLoopDetector:        crashLoopDetector,
+		config:                   config,
 	}
 }
 
@@ -33,6 +51,34 @@ func (s *ServiceService) Create(
 	ctx context.Context,
 	request dto.CreateServiceRequest,
 ) (*models.Service, error) {
+	// the service has no ID yet, so `uuid.Nil` stands in for it in the graph
+	service := models.Service{
+		ProjectID:     request.ProjectID,
+		Name:          request.Name,
+		Image:         request.Image,
+		Environment:   request.Environment,
+		Mounts:        request.Mounts,
+		Dependencies:  request.Dependencies,
+		NetworkAccess: request.NetworkAccess,
+		Ports:         request.Ports,
+		HealthCheck:   request.HealthCheck,
+		Resources:     request.Resources,
+		RestartPolicy: request.RestartPolicy,
+	}
+	// the ports column is NOT NULL, while nil slices are stored as NULL
+	if service.Ports == nil {
+		service.Ports = make([]models.ServicePort, 0)
+	}
+	if err := s.validateServiceSpec(service); err != nil {
+		return nil, err
+	}
+	if err := s.validateDNSAlias(ctx, service); err != nil {
+		return nil, err
+	}
+	if err := s.validateDependencies(ctx, service); err != nil {
+		return nil, err
+	}
+
 	return s.serviceRepository.Create(ctx, commands.CreateServiceCommand{
 		ProjectID:     request.ProjectID,
 		Name:          request.Name,
@@ -41,6 +87,10 @@ func (s *ServiceService) Create(
 		Mounts:        request.Mounts,
 		Dependencies:  request.Dependencies,
 		NetworkAccess: request.NetworkAccess,
+		Ports:         service.Ports,
+		HealthCheck:   request.HealthCheck,
+		Resources:     request.Resources,
+		RestartPolicy: request.RestartPolicy,
 	})
 }
 
@@ -52,21 +102,104 @@ func (s *ServiceService) ListAll(ctx context.Context) ([]models.Service, error)
 	return s.serviceRepository.ListAll(ctx)
 }
 
+// Start starts the service container. All (transitive) dependencies of the
+// service are started first in topological order, waiting for each of them
+// to satisfy its `ServiceCondition`.
 func (s *ServiceService) Start(ctx context.Context, id uuid.UUID, forcePull bool) (*models.Service, error) {
 	service, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO: implement transaction boundary
-	var containerID *string
+	dependencies, err := s.resolveDependencies(ctx, service)
+	if err != nil {
+		return nil, err
+	}
 
-	// create a new container if this is the first start or if forcePull is enabled
-	if service.ContainerID == nil || forcePull {
-		if err := s.dockerService.PullServiceImage(ctx, service); err != nil {
+	for _, dependency := range dependencies {
+		if err := s.startDependency(ctx, dependency); err != nil {
 			return nil, err
 		}
-		containerID, err = s.dockerService.CreateServiceContainer(ctx, service)
+	}
+
+	return s.startContainer(ctx, service, forcePull, true)
+}
+
+// startDependency starts a single dependency and waits until it satisfies
+// the required condition within the configured timeout. An existing
+// container of the dependency is only started, even if the dependency has
+// pending changes.
+func (s *ServiceService) startDependency(ctx context.Context, dependency resolvedDependency) error {
+	log.Info().Str("service_id", dependency.Service.ID.String()).
+		Str("condition", string(dependency.Condition)).
+		Msg("starting dependency service")
+
+	startedService, err := s.startContainer(ctx, &dependency.Service, false, false)
+	if err != nil {
+		return fmt.Errorf("failed to start dependency %q (%s): %w", dependency.Service.Name, dependency.Service.ID, err)
+	}
+	return s.waitForCondition(ctx, startedService, dependency.Condition)
+}
+
+// waitForCondition waits until the started service satisfies the given
+// condition within the configured dependency timeout.
+func (s *ServiceService) waitForCondition(
+	ctx context.Context,
+	service *models.Service,
+	condition models.ServiceCondition,
+) error {
+	waitCtx, cancel := context.WithTimeout(ctx, s.config.DependencyTimeout)
+	defer cancel()
+
+	err := s.dockerService.WaitForCondition(waitCtx, *service.ContainerID, condition)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return fmt.Errorf(
+			"%w: %q (%s) did not become %s within %s",
+			internal.ErrDependencyNotReady,
+			service.Name,
+			service.ID,
+			condition,
+			s.config.DependencyTimeout,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf(
+			"%w: %q (%s) did not become %s: %w",
+			internal.ErrDependencyNotReady,
+			service.Name,
+			service.ID,
+			condition,
+			err,
+		)
+	}
+	return nil
+}
+
+// startContainer starts (creating it first, if necessary) the container of
+// a single service, without considering its dependencies. If `redeploy` is
+// set, the container is recreated when the service has pending changes.
+func (s *ServiceService) startContainer(
+	ctx context.Context,
+	service *models.Service,
+	forcePull bool,
+	redeploy bool,
+) (*models.Service, error) {
+	// TODO: implement transaction boundary
+	var containerID *string
+
+	// the container is created from the allocated ports, also when it has to
+	// be created again because it was removed outside gidock
+	deployedService, err := s.allocateHostPorts(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a new container if this is the first start, if forcePull is
+	// enabled or if the service definition has changed since the last deploy
+	// and a redeploy was requested
+	recreated := service.ContainerID == nil || forcePull || (redeploy && service.NeedsRedeploy)
+	if recreated {
+		containerID, err = s.recreateContainer(ctx, deployedService)
 		if err != nil {
 			return nil, err
 		}
@@ -74,18 +207,26 @@ func (s *ServiceService) Start(ctx context.Context, id uuid.UUID, forcePull bool
 		containerID = service.ContainerID
 	}
 
-	startedContainerID, err := s.dockerService.StartServiceContainer(ctx, *containerID, service)
+	err = s.dockerService.StartContainer(ctx, *containerID)
+	// the container was removed outside gidock, so it's created again
+	if errdefs.IsNotFound(err) {
+		recreated = true
+		containerID, err = s.createContainer(ctx, deployedService)
+		if err == nil {
+			err = s.dockerService.StartContainer(ctx, *containerID)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	updatedService, err := s.serviceRepository.Update(
-		ctx,
-		commands.UpdateServiceCommand{
-			ID:          id,
-			ContainerID: startedContainerID,
-		},
-	)
+	command := commands.UpdateServiceCommand{ID: service.ID, ContainerID: containerID}
+	// pending changes are only applied when the container was recreated
+	if recreated {
+		needsRedeploy := false
+		command.NeedsRedeploy = &needsRedeploy
+	}
+	updatedService, err := s.serviceRepository.Update(ctx, command)
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +234,173 @@ func (s *ServiceService) Start(ctx context.Context, id uuid.UUID, forcePull bool
 	return updatedService, nil
 }
 
+// recreateContainer pulls the service image and creates a new container from
+// the current service definition, whose ports must already have their host
+// ports allocated. The previous container of the service (if any) is removed.
+// Host ports published by other services are checked before anything is
+// changed.
+func (s *ServiceService) recreateContainer(ctx context.Context, service *models.Service) (*string, error) {
+	if err := s.checkPortConflicts(ctx, service); err != nil {
+		return nil, err
+	}
+	if err := s.dockerService.PullServiceImage(ctx, service); err != nil {
+		return nil, err
+	}
+	if service.ContainerID != nil {
+		if err := s.dockerService.RemoveContainer(ctx, *service.ContainerID, false); err != nil {
+			return nil, err
+		}
+	}
+	return s.createContainer(ctx, service)
+}
+
+// createContainer creates a new container from the service definition, whose
+// ports must already have their host ports allocated. Host ports published by
+// other services are checked first, since Docker only detects conflicts once
+// the container is started.
+func (s *ServiceService) createContainer(ctx context.Context, service *models.Service) (*string, error) {
+	if err := s.checkPortConflicts(ctx, service); err != nil {
+		return nil, err
+	}
+	return s.dockerService.CreateServiceContainer(ctx, service)
+}
+
+// Update applies a partial update to the service. Changes affecting the
+// container mark the service as needing a redeploy; if `apply` is set, the
+// container is recreated immediately instead (and started again if it was
+// running).
+func (s *ServiceService) Update(
+	ctx context.Context,
+	id uuid.UUID,
+	request dto.UpdateServiceRequest,
+	apply bool,
+) (*models.Service, error) {
+	service, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateServiceUpdate(request); err != nil {
+		return nil, err
+	}
+	updatedService := applyServiceUpdate(*service, request)
+	if err := s.validateServiceSpec(updatedService); err != nil {
+		return nil, err
+	}
+	if request.Name != nil {
+		if err := s.validateDNSAlias(ctx, updatedService); err != nil {
+			return nil, err
+		}
+	}
+	if request.Dependencies != nil {
+		if err := s.validateDependencies(ctx, updatedService); err != nil {
+			return nil, err
+		}
+	}
+
+	command := commands.UpdateServiceCommand{
+		ID:                 id,
+		Name:               request.Name,
+		Image:              request.Image,
+		Environment:        request.Environment,
+		Mounts:             request.Mounts,
+		Dependencies:       request.Dependencies,
+		NetworkAccess:      request.NetworkAccess,
+		Ports:              request.Ports,
+		HealthCheck:        request.HealthCheck,
+		Resources:          request.Resources,
+		RestartPolicy:      request.RestartPolicy,
+		ClearHealthCheck:   request.ClearHealthCheck,
+		ClearResources:     request.ClearResources,
+		ClearRestartPolicy: request.ClearRestartPolicy,
+	}
+
+	// Docker containers can't be changed in place, so they must be recreated;
+	// the name is used as the DNS alias within the project network
+	containerChanged := request.Name != nil || request.Image != nil || request.Environment != nil ||
+		request.Mounts != nil || request.NetworkAccess != nil || request.Ports != nil || request.HealthCheck != nil ||
+		request.Resources != nil || request.RestartPolicy != nil ||
+		request.ClearHealthCheck || request.ClearResources || request.ClearRestartPolicy
+	if containerChanged && service.ContainerID != nil {
+		needsRedeploy := true
+		command.NeedsRedeploy = &needsRedeploy
+	}
+
+	savedService, err := s.serviceRepository.Update(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+
+	if !apply || !savedService.NeedsRedeploy {
+		return savedService, nil
+	}
+	return s.redeploy(ctx, savedService)
+}
+
+// applyServiceUpdate returns a copy of the service with all fields provided
+// in the update request applied.
+func applyServiceUpdate(service models.Service, request dto.UpdateServiceRequest) models.Service {
+	if request.Name != nil {
+		service.Name = *request.Name
+	}
+	if request.Image != nil {
+		service.Image = *request.Image
+	}
+	if request.Environment != nil {
+		service.Environment = *request.Environment
+	}
+	if request.Mounts != nil {
+		service.Mounts = *request.Mounts
+	}
+	if request.Dependencies != nil {
+		service.Dependencies = *request.Dependencies
+	}
+	if request.NetworkAccess != nil {
+		service.NetworkAccess = *request.NetworkAccess
+	}
+	if request.Ports != nil {
+		service.Ports = *request.Ports
+	}
+	if request.HealthCheck != nil || request.ClearHealthCheck {
+		service.HealthCheck = request.HealthCheck
+	}
+	if request.Resources != nil || request.ClearResources {
+		service.Resources = request.Resources
+	}
+	if request.RestartPolicy != nil || request.ClearRestartPolicy {
+		service.RestartPolicy = request.RestartPolicy
+	}
+	return service
+}
+
+// redeploy recreates the container of the service from its current
+// definition, starting the new container if the previous one was running.
+func (s *ServiceService) redeploy(ctx context.Context, service *models.Service) (*models.Service, error) {
+	status, err := s.dockerService.GetContainerStatus(ctx, *service.ContainerID)
+	if err != nil && !errdefs.IsNotFound(err) {
+		return nil, err
+	}
+	if status != nil && status.State == container.StateRunning {
+		return s.startContainer(ctx, service, false, true)
+	}
+
+	deployedService, err := s.allocateHostPorts(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	containerID, err := s.recreateContainer(ctx, deployedService)
+	if err != nil {
+		return nil, err
+	}
+
+	needsRedeploy := false
+	return s.serviceRepository.Update(ctx, commands.UpdateServiceCommand{
+		ID:            service.ID,
+		ContainerID:   containerID,
+		NeedsRedeploy: &needsRedeploy,
+	})
+}
+
 func (s *ServiceService) Stop(ctx context.Context, id uuid.UUID, kill bool) error {
 	service, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
 	if err != nil {
@@ -105,6 +413,92 @@ func (s *ServiceService) Stop(ctx context.Context, id uuid.UUID, kill bool) erro
 	return s.dockerService.StopContainer(ctx, *service.ContainerID, kill)
 }
 
+// Restart restarts the service container, giving it `timeout` seconds to
+// stop gracefully.
+func (s *ServiceService) Restart(ctx context.Context, id uuid.UUID, timeout *int, kill bool) error {
+	service, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
+	if err != nil {
+		return err
+	}
+	if service.ContainerID == nil {
+		return internal.ErrNoContainer
+	}
+	return s.dockerService.RestartContainer(ctx, *service.ContainerID, timeout, kill)
+}
+
+// Pause suspends all processes of the service container.
+func (s *ServiceService) Pause(ctx context.Context, id uuid.UUID) error {
+	service, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
+	if err != nil {
+		return err
+	}
+	if service.ContainerID == nil {
+		return internal.ErrNoContainer
+	}
+	return s.dockerService.PauseContainer(ctx, *service.ContainerID)
+}
+
+// Unpause resumes all processes of the paused service container.
+func (s *ServiceService) Unpause(ctx context.Context, id uuid.UUID) error {
+	service, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
+	if err != nil {
+		return err
+	}
+	if service.ContainerID == nil {
+		return internal.ErrNoContainer
+	}
+	return s.dockerService.UnpauseContainer(ctx, *service.ContainerID)
+}
+
+// Delete stops and removes the container of the service (along with its
+// anonymous volumes if `removeVolumes` is set) and deletes the service. A
+// service other services depend on can't be deleted.
+func (s *ServiceService) Delete(ctx context.Context, id uuid.UUID, removeVolumes bool) error {
+	service, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
+	if err != nil {
+		return err
+	}
+
+	projectServices, err := s.serviceRepository.ListByProject(
+		ctx,
+		commands.ListProjectServicesCommand{ProjectID: service.ProjectID},
+	)
+	if err != nil {
+		return err
+	}
+	for _, projectService := range projectServices {
+		for _, dependency := range projectService.Dependencies {
+			if dependency.ServiceID == id {
+				return fmt.Errorf("%w: %q depends on %q", internal.ErrServiceInUse, projectService.Name, service.Name)
+			}
+		}
+	}
+
+	if err := s.removeContainer(ctx, service, removeVolumes); err != nil {
+		return err
+	}
+	err = s.portAllocationRepository.ReleaseByService(
+		ctx,
+		commands.ReleaseServicePortAllocationsCommand{ServiceID: id},
+	)
+	if err != nil {
+		return err
+	}
+	return s.serviceRepository.Delete(ctx, commands.DeleteServiceCommand{ID: id})
+}
+
+// removeContainer gracefully stops and then removes the container of the
+// service, if it has one.
+func (s *ServiceService) removeContainer(ctx context.Context, service *models.Service, removeVolumes bool) error {
+	if service.ContainerID == nil {
+		return nil
+	}
+	if err := s.dockerService.StopContainer(ctx, *service.ContainerID, false); err != nil {
+		return err
+	}
+	return s.dockerService.RemoveContainer(ctx, *service.ContainerID, removeVolumes)
+}
+
 func (s *ServiceService) GetStatus(ctx context.Context, id uuid.UUID) (*dto.ServiceStatusResponse, error) {
 	service, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
 	if err != nil {
@@ -113,10 +507,198 @@ func (s *ServiceService) GetStatus(ctx context.Context, id uuid.UUID) (*dto.Serv
 	if service.ContainerID == nil {
 		return nil, internal.ErrNoContainer
 	}
-	return s.dockerService.GetContainerStatus(ctx, *service.ContainerID)
+	return s.containerStatus(ctx, *service.ContainerID)
 }
 
-func (s *ServiceService) StreamLogs(ctx context.Context, id uuid.UUID) (<-chan pkg.LogEntry, error) {
+// containerStatus returns the status of the container, reporting it as
+// crash looping if it keeps crashing.
+func (s *ServiceService) containerStatus(ctx context.Context, containerID string) (*dto.ServiceStatusResponse, error) {
+	status, err := s.dockerService.GetContainerStatus(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+	if s.crashLoopDetector.IsCrashLooping(containerID) {
+		status.State = dto.ServiceStateCrashLooping
+	}
+	return status, nil
+}
+
+// GetContainer returns a curated view of the service container details.
+func (s *ServiceService) GetContainer(ctx context.Context, id uuid.UUID) (*dto.ServiceContainerResponse, error) {
+	service, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
+	if err != nil {
+		return nil, err
+	}
+	if service.ContainerID == nil {
+		return nil, internal.ErrNoContainer
+	}
+	return s.dockerService.InspectContainer(ctx, *service.ContainerID)
+}
+
+// GetHealth returns the current health of the service container along with
+// at most `limit` of its most recent probe results.
+func (s *ServiceService) GetHealth(ctx context.Context, id uuid.UUID, limit int) (*dto.ServiceHealthResponse, error) {
+	service, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
+	if err != nil {
+		return nil, err
+	}
+	if service.ContainerID == nil {
+		return nil, internal.ErrNoContainer
+	}
+	return s.dockerService.GetContainerHealth(ctx, *service.ContainerID, limit)
+}
+
+// GetBatchStatus returns the status of every requested service, inspecting
+// containers concurrently with a bounded worker pool. Errors are reported per
+// service instead of failing the whole batch.
+func (s *ServiceService) GetBatchStatus(
+	ctx context.Context,
+	request dto.BatchServiceStatusRequest,
+) (map[uuid.UUID]dto.ServiceStatusResult, error) {
+	known := make(map[uuid.UUID]models.Service)
+	ids := make([]uuid.UUID, 0, len(request.ServiceIDs))
+
+	if request.ProjectID != nil {
+		projectServices, err := s.serviceRepository.ListByProject(
+			ctx,
+			commands.ListProjectServicesCommand{ProjectID: *request.ProjectID},
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, service := range projectServices {
+			known[service.ID] = service
+			ids = append(ids, service.ID)
+		}
+	}
+	for _, id := range request.ServiceIDs {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	results := make(map[uuid.UUID]dto.ServiceStatusResult, len(ids))
+	jobs := make(chan uuid.UUID)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for range min(batchStatusWorkers, len(ids)) {
+		wg.Go(func() {
+			for id := range jobs {
+				result := s.getStatusResult(ctx, id, known)
+				mu.Lock()
+				results[id] = result
+				mu.Unlock()
+			}
+		})
+	}
+	for _, id := range ids {
+		jobs <- id
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, nil
+}
+
+// getStatusResult retrieves the status of a single service for a batch
+// status report. Services already loaded are taken from `known`.
+func (s *ServiceService) getStatusResult(
+	ctx context.Context,
+	id uuid.UUID,
+	known map[uuid.UUID]models.Service,
+) dto.ServiceStatusResult {
+	service, ok := known[id]
+	if !ok {
+		fetchedService, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
+		if err != nil {
+			return dto.ServiceStatusResult{Error: err.Error()}
+		}
+		service = *fetchedService
+	}
+	if service.ContainerID == nil {
+		return dto.ServiceStatusResult{Error: internal.ErrNoContainer.Error()}
+	}
+
+	status, err := s.containerStatus(ctx, *service.ContainerID)
+	if err != nil {
+		return dto.ServiceStatusResult{Error: err.Error()}
+	}
+	return dto.ServiceStatusResult{Status: status}
+}
+
+// StreamLogs streams the logs of the service container selected by the
+// request.
+func (s *ServiceService) StreamLogs(
+	ctx context.Context,
+	id uuid.UUID,
+	request dto.ServiceLogsRequest,
+) (<-chan pkg.LogEntry, error) {
+	service, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
+	if err != nil {
+		return nil, err
+	}
+	if service.ContainerID == nil {
+		return nil, internal.ErrNoContainer
+	}
+	return s.dockerService.GetContainerLogs(ctx, *service.ContainerID, request)
+}
+
+// GetStats returns a single resource usage sample of the service container.
+func (s *ServiceService) GetStats(ctx context.Context, id uuid.UUID) (*dto.ServiceStatsResponse, error) {
+	service, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
+	if err != nil {
+		return nil, err
+	}
+	if service.ContainerID == nil {
+		return nil, internal.ErrNoContainer
+	}
+	return s.dockerService.GetContainerStats(ctx, *service.ContainerID)
+}
+
+// StreamStats streams resource usage samples of the service container until
+// the context is done.
+func (s *ServiceService) StreamStats(ctx context.Context, id uuid.UUID) (<-chan dto.ServiceStatsResponse, error) {
+	service, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
+	if err != nil {
+		return nil, err
+	}
+	if service.ContainerID == nil {
+		return nil, internal.ErrNoContainer
+	}
+	return s.dockerService.StreamContainerStats(ctx, *service.ContainerID)
+}
+
+// Exec starts an interactive command with a TTY of the given size in the
+// service container.
+func (s *ServiceService) Exec(
+	ctx context.Context,
+	id uuid.UUID,
+	command []string,
+	height uint,
+	width uint,
+) (*ExecSession, error) {
+	service, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
+	if err != nil {
+		return nil, err
+	}
+	if service.ContainerID == nil {
+		return nil, internal.ErrNoContainer
+	}
+	return s.dockerService.StartExecSession(ctx, *service.ContainerID, command, height, width)
+}
+
+// StartCommand starts a non-interactive command in the service container.
+// Its output and exit code are collected with `ExecSession.Wait`.
+func (s *ServiceService) StartCommand(
+	ctx context.Context,
+	id uuid.UUID,
+	request dto.RunCommandRequest,
+) (*ExecSession, error) {
+	if len(request.Command) == 0 {
+		return nil, fmt.Errorf("%w: the command must not be empty", internal.ErrInvalidCommand)
+	}
+
 	service, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id})
 	if err != nil {
 		return nil, err
@@ -124,5 +706,5 @@ func (s *ServiceService) StreamLogs(ctx context.Context, id uuid.UUID) (<-chan p
 	if service.ContainerID == nil {
 		return nil, internal.ErrNoContainer
 	}
-	return s.dockerService.GetContainerLogs(ctx, *service.ContainerID)
+	return s.dockerService.StartExecCommand(ctx, *service.ContainerID, request.Command, request.WorkingDir)
 }
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Pelfox/gidock/internal/dto"
+	"github.com/Pelfox/gidock/internal/models"
+)
+
+func TestApplyServiceUpdate(t *testing.T) {
+	healthCheck := &models.ServiceHealthCheck{Command: []string{"true"}}
+	resources := &models.ServiceResources{MemoryLimit: 64 * 1024 * 1024}
+	restartPolicy := &models.ServiceRestartPolicy{Name: models.RestartPolicyAlways}
+	service := models.Service{
+		Name:          "api",
+		HealthCheck:   healthCheck,
+		Resources:     resources,
+		RestartPolicy: restartPolicy,
+	}
+
+	tests := []struct {
+		name    string
+		request dto.UpdateServiceRequest
+		want    models.Service
+	}{
+		{name: "unchanged", want: service},
+		{
+			name:    "clear health check",
+			request: dto.UpdateServiceRequest{ClearHealthCheck: true},
+			want:    models.Service{Name: "api", Resources: resources, RestartPolicy: restartPolicy},
+		},
+		{
+			name:    "clear resources",
+			request: dto.UpdateServiceRequest{ClearResources: true},
+			want:    models.Service{Name: "api", HealthCheck: healthCheck, RestartPolicy: restartPolicy},
+		},
+		{
+			name:    "clear restart policy",
+			request: dto.UpdateServiceRequest{ClearRestartPolicy: true},
+			want:    models.Service{Name: "api", HealthCheck: healthCheck, Resources: resources},
+		},
+		{
+			name: "clear everything",
+			request: dto.UpdateServiceRequest{
+				ClearHealthCheck:   true,
+				ClearResources:     true,
+				ClearRestartPolicy: true,
+			},
+			want: models.Service{Name: "api"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := applyServiceUpdate(service, test.request)
+			if got.HealthCheck != test.want.HealthCheck {
+				t.Errorf("HealthCheck = %v, want %v", got.HealthCheck, test.want.HealthCheck)
+			}
+			if got.Resources != test.want.Resources {
+				t.Errorf("Resources = %v, want %v", got.Resources, test.want.Resources)
+			}
+			if got.RestartPolicy != test.want.RestartPolicy {
+				t.Errorf("RestartPolicy = %v, want %v", got.RestartPolicy, test.want.RestartPolicy)
+			}
+		})
+	}
+}
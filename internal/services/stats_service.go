@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Pelfox/gidock/internal"
+	"github.com/Pelfox/gidock/internal/dto"
+	"github.com/Pelfox/gidock/internal/models"
+	"github.com/Pelfox/gidock/internal/repositories"
+	"github.com/Pelfox/gidock/internal/repositories/commands"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// statsSamplerWorkers is the maximum number of containers sampled
+	// concurrently by `StatsService.Run`.
+	statsSamplerWorkers = 8
+	// defaultStatsWindow is the length of the history window when no start
+	// is requested.
+	defaultStatsWindow = time.Hour
+	// defaultStatsPoints is the number of buckets a history series is split
+	// into when no step is requested.
+	defaultStatsPoints = 60
+	// maxStatsPoints is the maximum number of buckets of a history series.
+	maxStatsPoints = 1000
+)
+
+// networkCounters are the cumulative network counters of a container, as
+// reported by Docker.
+type networkCounters struct {
+	containerID string
+	rx          uint64
+	tx          uint64
+}
+
+type StatsService struct {
+	projectRepository *repositories.ProjectRepository
+	serviceRepository *repositories.ServiceRepository
+	statsRepository   *repositories.StatsRepository
+	dockerService     *DockerService
+	config            *internal.AppConfig
+
+	// lastNetwork holds the counters of the previous sample of every service,
+	// so the traffic between two samples can be recorded. It is only used by
+	// the sampler goroutine.
+	lastNetwork map[uuid.UUID]networkCounters
+}
+
+func NewStatsService(
+	projectRepository *repositories.ProjectRepository,
+	serviceRepository *repositories.ServiceRepository,
+	statsRepository *repositories.StatsRepository,
+	dockerService *DockerService,
+	config *internal.AppConfig,
+) *StatsService {
+	return &StatsService{
+		projectRepository: projectRepository,
+		serviceRepository: serviceRepository,
+		statsRepository:   statsRepository,
+		dockerService:     dockerService,
+		config:            config,
+		lastNetwork:       make(map[uuid.UUID]networkCounters),
+	}
+}
+
+// Run records a stats sample of every service container at the configured
+// interval and prunes samples past the retention period, until the context
+// is done. It returns immediately if recording is disabled.
+func (s *StatsService) Run(ctx context.Context) {
+	if s.config.StatsInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.StatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.record(ctx); err != nil {
+				log.Error().Err(err).Msg("failed to record stats")
+			}
+			if err := s.prune(ctx); err != nil {
+				log.Error().Err(err).Msg("failed to prune stats")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// record samples every service container concurrently and stores the
+// samples.
+func (s *StatsService) record(ctx context.Context) error {
+	allServices, err := s.serviceRepository.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	deployedServices := make([]models.Service, 0, len(allServices))
+	for _, service := range allServices {
+		if service.ContainerID != nil {
+			deployedServices = append(deployedServices, service)
+		}
+	}
+
+	stats := make([]*dto.ServiceStatsResponse, len(deployedServices))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(statsSamplerWorkers, len(deployedServices)) {
+		wg.Go(func() {
+			for i := range jobs {
+				stats[i] = s.sampleService(ctx, deployedServices[i])
+			}
+		})
+	}
+	for i := range deployedServices {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	samples := make([]models.StatsSample, 0, len(deployedServices))
+	lastNetwork := make(map[uuid.UUID]networkCounters, len(deployedServices))
+	for i, service := range deployedServices {
+		if stats[i] == nil {
+			continue
+		}
+
+		counters := networkCounters{containerID: *service.ContainerID, rx: stats[i].NetworkRx, tx: stats[i].NetworkTx}
+		rx, tx := networkTraffic(s.lastNetwork[service.ID], counters)
+		lastNetwork[service.ID] = counters
+
+		samples = append(samples, models.StatsSample{
+			ServiceID:   service.ID,
+			SampledAt:   stats[i].Timestamp,
+			CPUPercent:  stats[i].CPUPercent,
+			MemoryUsage: int64(stats[i].MemoryUsage),
+			MemoryLimit: int64(stats[i].MemoryLimit),
+			NetworkRx:   int64(rx),
+			NetworkTx:   int64(tx),
+		})
+	}
+	// services that are gone or stopped start over once sampled again
+	s.lastNetwork = lastNetwork
+
+	return s.statsRepository.Record(ctx, commands.RecordStatsCommand{Samples: samples})
+}
+
+// sampleService returns a stats sample of the service container, or nil if
+// the container isn't running or can't be sampled.
+func (s *StatsService) sampleService(ctx context.Context, service models.Service) *dto.ServiceStatsResponse {
+	stats, err := s.dockerService.GetContainerStats(ctx, *service.ContainerID)
+	if err != nil {
+		log.Debug().Err(err).Str("service_id", service.ID.String()).Msg("failed to sample service stats")
+		return nil
+	}
+	// stopped containers report an empty sample
+	if stats.Timestamp.IsZero() || stats.Pids == 0 {
+		return nil
+	}
+	return stats
+}
+
+// networkTraffic returns the traffic between two samples of cumulative
+// counters. Nothing is reported for the first sample of a container, since
+// its counters cover an unknown period.
+func networkTraffic(previous, current networkCounters) (uint64, uint64) {
+	if previous.containerID != current.containerID {
+		return 0, 0
+	}
+	// the counters are reset when the container restarts
+	if current.rx < previous.rx || current.tx < previous.tx {
+		return current.rx, current.tx
+	}
+	return current.rx - previous.rx, current.tx - previous.tx
+}
+
+// prune removes the samples past the retention period.
+func (s *StatsService) prune(ctx context.Context) error {
+	pruned, err := s.statsRepository.Prune(ctx, commands.PruneStatsCommand{
+		Before: time.Now().Add(-s.config.StatsRetention),
+	})
+	if err != nil {
+		return err
+	}
+	if pruned > 0 {
+		log.Debug().Int64("samples", pruned).Msg("pruned stats samples")
+	}
+	return nil
+}
+
+// GetServiceHistory returns the downsampled stats of the service over the
+// requested time window.
+func (s *StatsService) GetServiceHistory(
+	ctx context.Context,
+	id uuid.UUID,
+	request dto.StatsHistoryRequest,
+) (*dto.StatsHistoryResponse, error) {
+	if _, err := s.serviceRepository.Get(ctx, commands.GetServiceCommand{ID: id}); err != nil {
+		return nil, err
+	}
+
+	response, err := s.resolveWindow(request)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Samples, err = s.statsRepository.GetServiceSeries(ctx, commands.GetServiceStatsCommand{
+		ServiceID: id,
+		From:      response.From,
+		To:        response.To,
+		Step:      time.Duration(response.Step) * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetProjectHistory returns the downsampled stats of all services of the
+// project, summed up, over the requested time window.
+func (s *StatsService) GetProjectHistory(
+	ctx context.Context,
+	id uuid.UUID,
+	request dto.StatsHistoryRequest,
+) (*dto.StatsHistoryResponse, error) {
+	if _, err := s.projectRepository.Get(ctx, commands.GetProjectCommand{ID: id}); err != nil {
+		return nil, err
+	}
+
+	response, err := s.resolveWindow(request)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Samples, err = s.statsRepository.GetProjectSeries(ctx, commands.GetProjectStatsCommand{
+		ProjectID: id,
+		From:      response.From,
+		To:        response.To,
+		Step:      time.Duration(response.Step) * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// resolveWindow fills in the defaults of the requested time window. The
+// step is at least the sampling interval, and large enough to keep the
+// series within `maxStatsPoints` buckets.
+func (s *StatsService) resolveWindow(request dto.StatsHistoryRequest) (*dto.StatsHistoryResponse, error) {
+	to := time.Now()
+	if request.To != nil {
+		to = *request.To
+	}
+	from := to.Add(-defaultStatsWindow)
+	if request.From != nil {
+		from = *request.From
+	}
+
+	window := to.Sub(from)
+	if window <= 0 {
+		return nil, fmt.Errorf("%w: the start must be before the end", internal.ErrInvalidTimeWindow)
+	}
+	if request.Step < 0 {
+		return nil, fmt.Errorf("%w: the step must not be negative", internal.ErrInvalidTimeWindow)
+	}
+
+	step := request.Step
+	if step == 0 {
+		step = window / defaultStatsPoints
+	}
+	step = max(step, s.config.StatsInterval, window/maxStatsPoints, time.Second)
+
+	return &dto.StatsHistoryResponse{
+		From:    from,
+		To:      to,
+		Step:    int(step.Round(time.Second).Seconds()),
+		Samples: make([]models.StatsSample, 0),
+	}, nil
+}
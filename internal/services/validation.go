@@ -0,0 +1,392 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/netip"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Pelfox/gidock/internal"
+	"github.com/Pelfox/gidock/internal/dto"
+	"github.com/Pelfox/gidock/internal/models"
+	"github.com/Pelfox/gidock/internal/repositories/commands"
+	"github.com/google/uuid"
+	"github.com/moby/moby/api/types/mount"
+)
+
+// Bounds of the resource limits accepted by Docker.
+const (
+	minMemoryLimit = 6 * 1024 * 1024
+	minCPUQuota    = 1000
+	minCPUPeriod   = 1000
+	maxCPUPeriod   = 1000000
+)
+
+// validateServiceSpec checks that the container-related settings of the
+// service are valid before it is persisted.
+func (s *ServiceService) validateServiceSpec(service models.Service) error {
+	if serviceDNSAlias(service.Name) == "" {
+		return fmt.Errorf("%w: service name %q can't be used as a DNS alias", internal.ErrInvalidServiceSpec, service.Name)
+	}
+	for _, serviceMount := range service.Mounts {
+		if err := s.validateMount(serviceMount); err != nil {
+			return err
+		}
+	}
+	if err := validatePorts(service); err != nil {
+		return err
+	}
+	if err := validateHealthCheck(service.HealthCheck); err != nil {
+		return err
+	}
+	if err := validateResources(service.Resources); err != nil {
+		return err
+	}
+	if err := validateRestartPolicy(service.RestartPolicy); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateServiceUpdate checks that the update request doesn't both replace
+// and clear the same setting.
+func validateServiceUpdate(request dto.UpdateServiceRequest) error {
+	switch {
+	case request.HealthCheck != nil && request.ClearHealthCheck:
+		return fmt.Errorf("%w: health_check and clear_health_check are mutually exclusive", internal.ErrInvalidServiceSpec)
+	case request.Resources != nil && request.ClearResources:
+		return fmt.Errorf("%w: resources and clear_resources are mutually exclusive", internal.ErrInvalidServiceSpec)
+	case request.RestartPolicy != nil && request.ClearRestartPolicy:
+		return fmt.Errorf("%w: restart_policy and clear_restart_policy are mutually exclusive", internal.ErrInvalidServiceSpec)
+	}
+	return nil
+}
+
+// validateDNSAlias checks that the DNS alias of the service doesn't collide
+// with the alias of another service of the same project.
+func (s *ServiceService) validateDNSAlias(ctx context.Context, service models.Service) error {
+	projectServices, err := s.serviceRepository.ListByProject(
+		ctx,
+		commands.ListProjectServicesCommand{ProjectID: service.ProjectID},
+	)
+	if err != nil {
+		return err
+	}
+
+	alias := serviceDNSAlias(service.Name)
+	for _, projectService := range projectServices {
+		if projectService.ID != service.ID && serviceDNSAlias(projectService.Name) == alias {
+			return fmt.Errorf(
+				"%w: service name %q collides with the DNS alias %q of service %q",
+				internal.ErrInvalidServiceSpec,
+				service.Name,
+				alias,
+				projectService.Name,
+			)
+		}
+	}
+	return nil
+}
+
+// validateMount checks that the mount has a known type, absolute paths and
+// options matching its type. Bind mount sources must be located under one of
+// the allowed host path prefixes.
+func (s *ServiceService) validateMount(serviceMount models.ServiceMount) error {
+	if !path.IsAbs(serviceMount.Target) {
+		return fmt.Errorf("%w: mount target %q must be an absolute path", internal.ErrInvalidServiceSpec, serviceMount.Target)
+	}
+
+	if serviceMount.Type != models.MountTypeBind && serviceMount.BindPropagation != "" {
+		return fmt.Errorf("%w: bind propagation is only supported for bind mounts", internal.ErrInvalidServiceSpec)
+	}
+	if serviceMount.Type != models.MountTypeTmpfs && (serviceMount.TmpfsSize != 0 || serviceMount.TmpfsMode != "") {
+		return fmt.Errorf("%w: tmpfs options are only supported for tmpfs mounts", internal.ErrInvalidServiceSpec)
+	}
+
+	switch serviceMount.Type {
+	case "", models.MountTypeVolume:
+		return nil
+	case models.MountTypeBind:
+		if !path.IsAbs(serviceMount.Source) {
+			return fmt.Errorf("%w: bind mount source %q must be an absolute path", internal.ErrInvalidServiceSpec, serviceMount.Source)
+		}
+		if !s.isBindPathAllowed(serviceMount.Source) {
+			return fmt.Errorf("%w: bind mount source %q is not allowed", internal.ErrInvalidServiceSpec, serviceMount.Source)
+		}
+		propagation := mount.Propagation(serviceMount.BindPropagation)
+		if propagation != "" && !slices.Contains(mount.Propagations, propagation) {
+			return fmt.Errorf("%w: unknown bind propagation %q", internal.ErrInvalidServiceSpec, propagation)
+		}
+		return nil
+	case models.MountTypeTmpfs:
+		if serviceMount.Source != "" {
+			return fmt.Errorf("%w: tmpfs mounts must not have a source", internal.ErrInvalidServiceSpec)
+		}
+		if serviceMount.TmpfsSize < 0 {
+			return fmt.Errorf("%w: tmpfs size must not be negative", internal.ErrInvalidServiceSpec)
+		}
+		if serviceMount.TmpfsMode != "" {
+			if _, err := strconv.ParseUint(serviceMount.TmpfsMode, 8, 32); err != nil {
+				return fmt.Errorf("%w: invalid tmpfs mode %q", internal.ErrInvalidServiceSpec, serviceMount.TmpfsMode)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown mount type %q", internal.ErrInvalidServiceSpec, serviceMount.Type)
+	}
+}
+
+// isBindPathAllowed reports whether the host path is located under one of
+// the allowed bind mount prefixes. Symlinks are resolved on both sides, so a
+// link inside an allowed directory can't point outside of it.
+func (s *ServiceService) isBindPathAllowed(hostPath string) bool {
+	hostPath, err := resolveHostPath(hostPath)
+	if err != nil {
+		return false
+	}
+	for _, prefix := range s.config.AllowedBindPaths {
+		prefix = strings.TrimSpace(prefix)
+		if !path.IsAbs(prefix) {
+			continue
+		}
+		prefix, err := resolveHostPath(prefix)
+		if err != nil {
+			continue
+		}
+		if prefix == "/" || hostPath == prefix || strings.HasPrefix(hostPath, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveHostPath returns the host path with all symlinks and `..` elements
+// resolved, the way the kernel resolves them. Missing trailing elements are
+// kept as they are, since they can't be links.
+func resolveHostPath(hostPath string) (string, error) {
+	// the path must not be cleaned beforehand: `link/..` is the parent of
+	// the link target, not the directory containing the link
+	resolved, err := filepath.EvalSymlinks(hostPath)
+	if err == nil {
+		return resolved, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	parent, name := filepath.Split(strings.TrimRight(hostPath, "/"))
+	if name == "" || name == "." || name == ".." {
+		return "", err
+	}
+	resolvedParent, err := resolveHostPath(parent)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolvedParent, name), nil
+}
+
+// validateHealthCheck checks that the health check (if any) has no negative
+// durations or retries.
+func validateHealthCheck(healthCheck *models.ServiceHealthCheck) error {
+	if healthCheck == nil {
+		return nil
+	}
+	if healthCheck.Interval < 0 || healthCheck.Timeout < 0 || healthCheck.StartPeriod < 0 {
+		return fmt.Errorf("%w: health check durations must not be negative", internal.ErrInvalidServiceSpec)
+	}
+	if healthCheck.Retries < 0 {
+		return fmt.Errorf("%w: health check retries must not be negative", internal.ErrInvalidServiceSpec)
+	}
+	return nil
+}
+
+// validateResources checks that the resource limits (if any) are within the
+// bounds accepted by Docker and don't contradict each other.
+func validateResources(resources *models.ServiceResources) error {
+	if resources == nil {
+		return nil
+	}
+	if resources.MemoryLimit < 0 || resources.MemoryReservation < 0 || resources.NanoCPUs < 0 ||
+		resources.CPUQuota < 0 || resources.CPUPeriod < 0 || resources.PidsLimit < 0 || resources.ShmSize < 0 {
+		return fmt.Errorf("%w: resource limits must not be negative", internal.ErrInvalidServiceSpec)
+	}
+
+	if resources.MemoryLimit != 0 && resources.MemoryLimit < minMemoryLimit {
+		return fmt.Errorf("%w: memory limit must be at least %d bytes", internal.ErrInvalidServiceSpec, minMemoryLimit)
+	}
+	if resources.MemoryLimit != 0 && resources.MemoryReservation > resources.MemoryLimit {
+		return fmt.Errorf("%w: memory reservation must not exceed the memory limit", internal.ErrInvalidServiceSpec)
+	}
+
+	if resources.NanoCPUs != 0 && (resources.CPUQuota != 0 || resources.CPUPeriod != 0) {
+		return fmt.Errorf("%w: nano CPUs can't be combined with a CPU quota", internal.ErrInvalidServiceSpec)
+	}
+	if resources.CPUPeriod != 0 && resources.CPUQuota == 0 {
+		return fmt.Errorf("%w: CPU period requires a CPU quota", internal.ErrInvalidServiceSpec)
+	}
+	if resources.CPUQuota != 0 && resources.CPUQuota < minCPUQuota {
+		return fmt.Errorf("%w: CPU quota must be at least %d microseconds", internal.ErrInvalidServiceSpec, minCPUQuota)
+	}
+	if resources.CPUPeriod != 0 && (resources.CPUPeriod < minCPUPeriod || resources.CPUPeriod > maxCPUPeriod) {
+		return fmt.Errorf(
+			"%w: CPU period must be between %d and %d microseconds",
+			internal.ErrInvalidServiceSpec,
+			minCPUPeriod,
+			maxCPUPeriod,
+		)
+	}
+	return nil
+}
+
+// validateRestartPolicy checks that the restart policy (if any) is known and
+// only limits retries for the `on-failure` policy.
+func validateRestartPolicy(restartPolicy *models.ServiceRestartPolicy) error {
+	if restartPolicy == nil {
+		return nil
+	}
+	if !restartPolicy.Name.IsValid() {
+		return fmt.Errorf("%w: unknown restart policy %q", internal.ErrInvalidServiceSpec, restartPolicy.Name)
+	}
+	if restartPolicy.MaxRetries < 0 {
+		return fmt.Errorf("%w: restart policy retries must not be negative", internal.ErrInvalidServiceSpec)
+	}
+	if restartPolicy.MaxRetries != 0 && restartPolicy.Name != models.RestartPolicyOnFailure {
+		return fmt.Errorf(
+			"%w: maximum retries are only supported by the %q restart policy",
+			internal.ErrInvalidServiceSpec,
+			models.RestartPolicyOnFailure,
+		)
+	}
+	return nil
+}
+
+// validatePorts checks that every published port has a valid port number,
+// protocol and host address, and that no port is published twice.
+func validatePorts(service models.Service) error {
+	if len(service.Ports) > 0 && !service.NetworkAccess {
+		return fmt.Errorf("%w: publishing ports requires network access", internal.ErrInvalidServiceSpec)
+	}
+
+	for i, servicePort := range service.Ports {
+		if servicePort.ContainerPort < 1 || servicePort.ContainerPort > 65535 {
+			return fmt.Errorf("%w: invalid container port %d", internal.ErrInvalidServiceSpec, servicePort.ContainerPort)
+		}
+		if servicePort.HostPort < 0 || servicePort.HostPort > 65535 {
+			return fmt.Errorf("%w: invalid host port %d", internal.ErrInvalidServiceSpec, servicePort.HostPort)
+		}
+		switch servicePort.PortProtocol() {
+		case "tcp", "udp", "sctp":
+		default:
+			return fmt.Errorf("%w: unknown port protocol %q", internal.ErrInvalidServiceSpec, servicePort.Protocol)
+		}
+		if servicePort.HostIP != "" {
+			if _, err := netip.ParseAddr(servicePort.HostIP); err != nil {
+				return fmt.Errorf("%w: invalid host IP %q", internal.ErrInvalidServiceSpec, servicePort.HostIP)
+			}
+		}
+
+		for _, other := range service.Ports[:i] {
+			if other.ContainerPort == servicePort.ContainerPort && other.PortProtocol() == servicePort.PortProtocol() {
+				return fmt.Errorf(
+					"%w: container port %d/%s is published more than once",
+					internal.ErrInvalidServiceSpec,
+					servicePort.ContainerPort,
+					servicePort.PortProtocol(),
+				)
+			}
+			if portsOverlap(other, servicePort) {
+				return fmt.Errorf(
+					"%w: host port %d/%s is published more than once",
+					internal.ErrInvalidServiceSpec,
+					servicePort.HostPort,
+					servicePort.PortProtocol(),
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// checkPortConflicts returns an error if any host port of the service is
+// already published by, or allocated to, another gidock service.
+func (s *ServiceService) checkPortConflicts(ctx context.Context, service *models.Service) error {
+	if len(service.Ports) == 0 {
+		return nil
+	}
+
+	allServices, err := s.serviceRepository.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	names := make(map[uuid.UUID]string, len(allServices))
+	for _, other := range allServices {
+		names[other.ID] = other.Name
+	}
+
+	allocations, err := s.portAllocationRepository.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, allocation := range allocations {
+		if allocation.ServiceID == service.ID {
+			continue
+		}
+		for _, servicePort := range service.Ports {
+			if servicePort.HostPort == allocation.HostPort && servicePort.PortProtocol() == allocation.Protocol {
+				return fmt.Errorf(
+					"%w: host port %d/%s is allocated to service %q",
+					internal.ErrPortConflict,
+					servicePort.HostPort,
+					servicePort.PortProtocol(),
+					names[allocation.ServiceID],
+				)
+			}
+		}
+	}
+
+	for _, other := range allServices {
+		if other.ID == service.ID {
+			continue
+		}
+		for _, otherPort := range other.Ports {
+			for _, servicePort := range service.Ports {
+				if portsOverlap(otherPort, servicePort) {
+					return fmt.Errorf(
+						"%w: host port %d/%s is already published by service %q",
+						internal.ErrPortConflict,
+						servicePort.HostPort,
+						servicePort.PortProtocol(),
+						other.Name,
+					)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// portsOverlap reports whether both ports bind the same host port and
+// protocol on overlapping host addresses. Ports without a host port never
+// overlap, since Docker picks a free one for them.
+func portsOverlap(a, b models.ServicePort) bool {
+	if a.HostPort == 0 || a.HostPort != b.HostPort || a.PortProtocol() != b.PortProtocol() {
+		return false
+	}
+	return isUnspecifiedHostIP(a.HostIP) || isUnspecifiedHostIP(b.HostIP) || a.HostIP == b.HostIP
+}
+
+// isUnspecifiedHostIP reports whether the host address binds to every
+// address of the host.
+func isUnspecifiedHostIP(hostIP string) bool {
+	if hostIP == "" {
+		return true
+	}
+	addr, err := netip.ParseAddr(hostIP)
+	return err == nil && addr.IsUnspecified()
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Pelfox/gidock/internal"
+	"github.com/Pelfox/gidock/internal/dto"
+	"github.com/Pelfox/gidock/internal/models"
+)
+
+func TestIsBindPathAllowed(t *testing.T) {
+	root := t.TempDir()
+	allowed := filepath.Join(root, "data")
+	outside := filepath.Join(root, "secret")
+	for _, dir := range []string{allowed, outside, filepath.Join(allowed, "app"), filepath.Join(root, "data2")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(allowed, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(allowed, "app"), filepath.Join(allowed, "inside")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/", filepath.Join(allowed, "root")); err != nil {
+		t.Fatal(err)
+	}
+
+	service := &ServiceService{config: &internal.AppConfig{AllowedBindPaths: []string{allowed}}}
+
+	tests := []struct {
+		name     string
+		hostPath string
+		want     bool
+	}{
+		{"prefix itself", allowed, true},
+		{"nested directory", filepath.Join(allowed, "app"), true},
+		{"missing nested path", filepath.Join(allowed, "app", "missing", "file"), true},
+		{"link inside prefix", filepath.Join(allowed, "inside"), true},
+		{"sibling with shared prefix", filepath.Join(root, "data2"), false},
+		{"parent escape", filepath.Join(allowed, "..", "secret"), false},
+		{"link pointing outside", filepath.Join(allowed, "escape"), false},
+		{"path below link pointing outside", filepath.Join(allowed, "escape", "file"), false},
+		{"link to root", filepath.Join(allowed, "root"), false},
+		{"parent of link target", allowed + "/escape/../secret", false},
+		{"missing path behind link", allowed + "/escape/missing", false},
+		{"outside prefix", "/etc", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := service.isBindPathAllowed(test.hostPath); got != test.want {
+				t.Errorf("isBindPathAllowed(%q) = %v, want %v", test.hostPath, got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsBindPathAllowedWithoutPrefixes(t *testing.T) {
+	service := &ServiceService{config: &internal.AppConfig{}}
+	if service.isBindPathAllowed(t.TempDir()) {
+		t.Error("isBindPathAllowed() = true without allowed prefixes")
+	}
+}
+
+func TestValidatePorts(t *testing.T) {
+	tests := []struct {
+		name          string
+		networkAccess bool
+		ports         []models.ServicePort
+		wantErr       bool
+	}{
+		{name: "no ports", ports: nil},
+		{
+			name:          "valid ports",
+			networkAccess: true,
+			ports: []models.ServicePort{
+				{ContainerPort: 80, HostPort: 8080},
+				{ContainerPort: 80, Protocol: "udp", HostPort: 8080},
+				{ContainerPort: 443},
+				{ContainerPort: 5432, HostPort: 5432, HostIP: "127.0.0.1"},
+				{ContainerPort: 5433, HostPort: 5432, HostIP: "127.0.0.2"},
+			},
+		},
+		{
+			name:    "ports without network access",
+			ports:   []models.ServicePort{{ContainerPort: 80}},
+			wantErr: true,
+		},
+		{
+			name:          "container port out of range",
+			networkAccess: true,
+			ports:         []models.ServicePort{{ContainerPort: 65536}},
+			wantErr:       true,
+		},
+		{
+			name:          "missing container port",
+			networkAccess: true,
+			ports:         []models.ServicePort{{HostPort: 8080}},
+			wantErr:       true,
+		},
+		{
+			name:          "negative host port",
+			networkAccess: true,
+			ports:         []models.ServicePort{{ContainerPort: 80, HostPort: -1}},
+			wantErr:       true,
+		},
+		{
+			name:          "unknown protocol",
+			networkAccess: true,
+			ports:         []models.ServicePort{{ContainerPort: 80, Protocol: "icmp"}},
+			wantErr:       true,
+		},
+		{
+			name:          "invalid host IP",
+			networkAccess: true,
+			ports:         []models.ServicePort{{ContainerPort: 80, HostIP: "localhost"}},
+			wantErr:       true,
+		},
+		{
+			name:          "container port published twice",
+			networkAccess: true,
+			ports:         []models.ServicePort{{ContainerPort: 80}, {ContainerPort: 80, Protocol: "tcp"}},
+			wantErr:       true,
+		},
+		{
+			name:          "host port published twice",
+			networkAccess: true,
+			ports: []models.ServicePort{
+				{ContainerPort: 80, HostPort: 8080, HostIP: "127.0.0.1"},
+				{ContainerPort: 81, HostPort: 8080},
+			},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := models.Service{NetworkAccess: test.networkAccess, Ports: test.ports}
+			err := validatePorts(service)
+			if test.wantErr && !errors.Is(err, internal.ErrInvalidServiceSpec) {
+				t.Errorf("validatePorts() error = %v, want %v", err, internal.ErrInvalidServiceSpec)
+			}
+			if !test.wantErr && err != nil {
+				t.Errorf("validatePorts() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateResources(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources *models.ServiceResources
+		wantErr   bool
+	}{
+		{name: "no resources"},
+		{name: "no limits", resources: &models.ServiceResources{}},
+		{
+			name: "valid limits",
+			resources: &models.ServiceResources{
+				MemoryLimit:       512 * 1024 * 1024,
+				MemoryReservation: 256 * 1024 * 1024,
+				NanoCPUs:          1_500_000_000,
+				PidsLimit:         100,
+				ShmSize:           64 * 1024 * 1024,
+			},
+		},
+		{name: "valid CPU quota", resources: &models.ServiceResources{CPUQuota: 50000, CPUPeriod: 100000}},
+		{name: "CPU quota with default period", resources: &models.ServiceResources{CPUQuota: 50000}},
+		{name: "reservation without limit", resources: &models.ServiceResources{MemoryReservation: 1024}},
+		{name: "negative limit", resources: &models.ServiceResources{PidsLimit: -1}, wantErr: true},
+		{name: "memory limit too low", resources: &models.ServiceResources{MemoryLimit: 1024}, wantErr: true},
+		{
+			name:      "reservation above limit",
+			resources: &models.ServiceResources{MemoryLimit: 64 * 1024 * 1024, MemoryReservation: 128 * 1024 * 1024},
+			wantErr:   true,
+		},
+		{
+			name:      "nano CPUs with CPU quota",
+			resources: &models.ServiceResources{NanoCPUs: 1_000_000_000, CPUQuota: 50000},
+			wantErr:   true,
+		},
+		{name: "CPU period without quota", resources: &models.ServiceResources{CPUPeriod: 100000}, wantErr: true},
+		{name: "CPU quota too low", resources: &models.ServiceResources{CPUQuota: 999}, wantErr: true},
+		{
+			name:      "CPU period too high",
+			resources: &models.ServiceResources{CPUQuota: 50000, CPUPeriod: 1000001},
+			wantErr:   true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateResources(test.resources)
+			if test.wantErr && !errors.Is(err, internal.ErrInvalidServiceSpec) {
+				t.Errorf("validateResources() error = %v, want %v", err, internal.ErrInvalidServiceSpec)
+			}
+			if !test.wantErr && err != nil {
+				t.Errorf("validateResources() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateRestartPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		restartPolicy *models.ServiceRestartPolicy
+		wantErr       bool
+	}{
+		{name: "no restart policy"},
+		{name: "no", restartPolicy: &models.ServiceRestartPolicy{Name: models.RestartPolicyNo}},
+		{name: "always", restartPolicy: &models.ServiceRestartPolicy{Name: models.RestartPolicyAlways}},
+		{name: "unless stopped", restartPolicy: &models.ServiceRestartPolicy{Name: models.RestartPolicyUnlessStopped}},
+		{
+			name:          "on failure with retries",
+			restartPolicy: &models.ServiceRestartPolicy{Name: models.RestartPolicyOnFailure, MaxRetries: 5},
+		},
+		{name: "unknown policy", restartPolicy: &models.ServiceRestartPolicy{Name: "sometimes"}, wantErr: true},
+		{name: "empty policy", restartPolicy: &models.ServiceRestartPolicy{}, wantErr: true},
+		{
+			name:          "negative retries",
+			restartPolicy: &models.ServiceRestartPolicy{Name: models.RestartPolicyOnFailure, MaxRetries: -1},
+			wantErr:       true,
+		},
+		{
+			name:          "retries with another policy",
+			restartPolicy: &models.ServiceRestartPolicy{Name: models.RestartPolicyAlways, MaxRetries: 3},
+			wantErr:       true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateRestartPolicy(test.restartPolicy)
+			if test.wantErr && !errors.Is(err, internal.ErrInvalidServiceSpec) {
+				t.Errorf("validateRestartPolicy() error = %v, want %v", err, internal.ErrInvalidServiceSpec)
+			}
+			if !test.wantErr && err != nil {
+				t.Errorf("validateRestartPolicy() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateServiceUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request dto.UpdateServiceRequest
+		wantErr bool
+	}{
+		{name: "no changes"},
+		{name: "replace resources", request: dto.UpdateServiceRequest{Resources: &models.ServiceResources{}}},
+		{name: "clear everything", request: dto.UpdateServiceRequest{
+			ClearHealthCheck:   true,
+			ClearResources:     true,
+			ClearRestartPolicy: true,
+		}},
+		{
+			name: "replace and clear health check",
+			request: dto.UpdateServiceRequest{
+				HealthCheck:      &models.ServiceHealthCheck{Command: []string{"true"}},
+				ClearHealthCheck: true,
+			},
+			wantErr: true,
+		},
+		{
+			name:    "replace and clear resources",
+			request: dto.UpdateServiceRequest{Resources: &models.ServiceResources{}, ClearResources: true},
+			wantErr: true,
+		},
+		{
+			name: "replace and clear restart policy",
+			request: dto.UpdateServiceRequest{
+				RestartPolicy:      &models.ServiceRestartPolicy{Name: models.RestartPolicyAlways},
+				ClearRestartPolicy: true,
+			},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateServiceUpdate(test.request)
+			if test.wantErr && !errors.Is(err, internal.ErrInvalidServiceSpec) {
+				t.Errorf("validateServiceUpdate() error = %v, want %v", err, internal.ErrInvalidServiceSpec)
+			}
+			if !test.wantErr && err != nil {
+				t.Errorf("validateServiceUpdate() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/Pelfox/gidock/internal"
+	"github.com/Pelfox/gidock/internal/dto"
+	"github.com/Pelfox/gidock/internal/models"
+	"github.com/Pelfox/gidock/internal/repositories"
+	"github.com/Pelfox/gidock/internal/repositories/commands"
+	"github.com/containerd/errdefs"
+	"github.com/google/uuid"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/volume"
+	"github.com/rs/zerolog/log"
+)
+
+// volumeNamePattern matches the volume names accepted by Docker.
+var volumeNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+type VolumeService struct {
+	projectRepository *repositories.ProjectRepository
+	serviceRepository *repositories.ServiceRepository
+	dockerService     *DockerService
+	config            *internal.AppConfig
+}
+
+func NewVolumeService(
+	projectRepository *repositories.ProjectRepository,
+	serviceRepository *repositories.ServiceRepository,
+	dockerService *DockerService,
+	config *internal.AppConfig,
+) *VolumeService {
+	return &VolumeService{
+		projectRepository: projectRepository,
+		serviceRepository: serviceRepository,
+		dockerService:     dockerService,
+		config:            config,
+	}
+}
+
+// Create creates a new named volume scoped to the project.
+func (s *VolumeService) Create(
+	ctx context.Context,
+	projectID uuid.UUID,
+	request dto.CreateVolumeRequest,
+) (*dto.VolumeResponse, error) {
+	if !volumeNamePattern.MatchString(request.Name) {
+		return nil, fmt.Errorf("%w: %q", internal.ErrInvalidVolumeName, request.Name)
+	}
+
+	projectServices, err := s.listProjectServices(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Docker silently returns existing volumes on create, so check beforehand
+	_, err = s.dockerService.InspectVolume(ctx, request.Name)
+	if err == nil {
+		return nil, fmt.Errorf("%w: %q", internal.ErrVolumeExists, request.Name)
+	}
+	if !errdefs.IsNotFound(err) {
+		return nil, err
+	}
+
+	createdVolume, err := s.dockerService.CreateProjectVolume(ctx, projectID, request.Name, request.Driver)
+	if err != nil {
+		return nil, err
+	}
+	response := newVolumeResponse(*createdVolume, projectServices)
+	return &response, nil
+}
+
+// List returns all volumes of the project along with their size and the
+// services mounting them.
+func (s *VolumeService) List(ctx context.Context, projectID uuid.UUID) ([]dto.VolumeResponse, error) {
+	projectServices, err := s.listProjectServices(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, err := s.dockerService.ListProjectVolumes(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.VolumeResponse, 0, len(volumes))
+	for _, projectVolume := range volumes {
+		response = append(response, newVolumeResponse(projectVolume, projectServices))
+	}
+	return response, nil
+}
+
+// Delete removes the project volume. Volumes mounted by any service of the
+// project can't be deleted.
+func (s *VolumeService) Delete(ctx context.Context, projectID uuid.UUID, name string) error {
+	projectServices, err := s.listProjectServices(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.getProjectVolume(ctx, projectID, name); err != nil {
+		return err
+	}
+
+	if users := volumeUsers(name, projectServices); len(users) > 0 {
+		return fmt.Errorf("%w: %q is mounted by %q", internal.ErrVolumeInUse, name, users[0].Name)
+	}
+	return s.dockerService.RemoveVolume(ctx, name)
+}
+
+// Backup returns a gzip-compressed tar archive of the volume contents. If
+// `stopServices` is set, running services mounting the volume are stopped
+// until the returned archive is closed.
+func (s *VolumeService) Backup(ctx context.Context, name string, stopServices bool) (io.ReadCloser, error) {
+	users, err := s.getVolumeUsers(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var stopped []models.Service
+	if stopServices {
+		stopped, err = s.stopServices(ctx, users)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	archive, err := s.dockerService.ExportVolume(ctx, name, s.config.VolumeHelperImage)
+	if err != nil {
+		s.restartServices(ctx, stopped)
+		return nil, err
+	}
+
+	return &cleanupReadCloser{
+		ReadCloser: archive,
+		cleanup: func() {
+			s.restartServices(ctx, stopped)
+		},
+	}, nil
+}
+
+// Restore replaces the volume contents with the given gzip-compressed tar
+// archive. If `stopServices` is set, running services mounting the volume
+// are stopped for the duration of the restore.
+func (s *VolumeService) Restore(ctx context.Context, name string, stopServices bool, archive io.Reader) error {
+	users, err := s.getVolumeUsers(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if stopServices {
+		stopped, err := s.stopServices(ctx, users)
+		if err != nil {
+			return err
+		}
+		defer s.restartServices(ctx, stopped)
+	}
+
+	return s.dockerService.ImportVolume(ctx, name, s.config.VolumeHelperImage, archive)
+}
+
+// getVolumeUsers ensures the volume is managed by gidock and returns the
+// services of its project mounting it.
+func (s *VolumeService) getVolumeUsers(ctx context.Context, name string) ([]models.Service, error) {
+	managedVolume, err := s.dockerService.InspectVolume(ctx, name)
+	if errdefs.IsNotFound(err) {
+		return nil, internal.ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := uuid.Parse(managedVolume.Labels["gidock.project_id"])
+	if err != nil || managedVolume.Labels["gidock.volume"] != "true" {
+		return nil, internal.ErrRecordNotFound
+	}
+
+	projectServices, err := s.serviceRepository.ListByProject(
+		ctx,
+		commands.ListProjectServicesCommand{ProjectID: projectID},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]models.Service, 0)
+	for _, service := range projectServices {
+		if mountsVolume(service, name) {
+			users = append(users, service)
+		}
+	}
+	return users, nil
+}
+
+// stopServices stops the running services and returns them. If any service
+// fails to stop, the already stopped ones are started again.
+func (s *VolumeService) stopServices(ctx context.Context, services []models.Service) ([]models.Service, error) {
+	stopped := make([]models.Service, 0, len(services))
+	for _, service := range services {
+		if service.ContainerID == nil {
+			continue
+		}
+
+		status, err := s.dockerService.GetContainerStatus(ctx, *service.ContainerID)
+		if errdefs.IsNotFound(err) {
+			continue
+		}
+		if err == nil && status.State != container.StateRunning {
+			continue
+		}
+		if err == nil {
+			err = s.dockerService.StopContainer(ctx, *service.ContainerID, false)
+		}
+		if err != nil {
+			s.restartServices(ctx, stopped)
+			return nil, fmt.Errorf("failed to stop service %q (%s): %w", service.Name, service.ID, err)
+		}
+		stopped = append(stopped, service)
+	}
+	return stopped, nil
+}
+
+// restartServices starts the previously stopped services again, logging any
+// failure. It also runs when the request context is already canceled.
+func (s *VolumeService) restartServices(ctx context.Context, services []models.Service) {
+	ctx = context.WithoutCancel(ctx)
+	for _, service := range services {
+		if err := s.dockerService.StartContainer(ctx, *service.ContainerID); err != nil {
+			log.Error().Err(err).Str("service_id", service.ID.String()).
+				Msg("failed to restart service after volume operation")
+		}
+	}
+}
+
+// getProjectVolume returns the volume with the given name, if it belongs to
+// the project.
+func (s *VolumeService) getProjectVolume(ctx context.Context, projectID uuid.UUID, name string) (*volume.Volume, error) {
+	projectVolume, err := s.dockerService.InspectVolume(ctx, name)
+	if errdefs.IsNotFound(err) {
+		return nil, internal.ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if projectVolume.Labels["gidock.project_id"] != projectID.String() {
+		return nil, internal.ErrRecordNotFound
+	}
+	return projectVolume, nil
+}
+
+// listProjectServices ensures the project exists and returns its services.
+func (s *VolumeService) listProjectServices(ctx context.Context, projectID uuid.UUID) ([]models.Service, error) {
+	_, err := s.projectRepository.Get(ctx, commands.GetProjectCommand{ID: projectID})
+	if err != nil {
+		return nil, err
+	}
+	return s.serviceRepository.ListByProject(ctx, commands.ListProjectServicesCommand{ProjectID: projectID})
+}
+
+// volumeUsers returns the services mounting the named volume.
+func volumeUsers(name string, projectServices []models.Service) []dto.VolumeUser {
+	users := make([]dto.VolumeUser, 0)
+	for _, service := range projectServices {
+		if mountsVolume(service, name) {
+			users = append(users, dto.VolumeUser{ServiceID: service.ID, Name: service.Name})
+		}
+	}
+	return users
+}
+
+// mountsVolume reports whether the service mounts the named volume.
+func mountsVolume(service models.Service, name string) bool {
+	for _, serviceMount := range service.Mounts {
+		isVolume := serviceMount.Type == "" || serviceMount.Type == models.MountTypeVolume
+		if isVolume && serviceMount.Source == name {
+			return true
+		}
+	}
+	return false
+}
+
+// newVolumeResponse maps the Docker volume to its response representation.
+func newVolumeResponse(projectVolume volume.Volume, projectServices []models.Service) dto.VolumeResponse {
+	size := int64(-1)
+	if projectVolume.UsageData != nil {
+		size = projectVolume.UsageData.Size
+	}
+	return dto.VolumeResponse{
+		Name:      projectVolume.Name,
+		Driver:    projectVolume.Driver,
+		CreatedAt: projectVolume.CreatedAt,
+		Size:      size,
+		UsedBy:    volumeUsers(projectVolume.Name, projectServices),
+	}
+}
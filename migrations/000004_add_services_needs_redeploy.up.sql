@@ -0,0 +1 @@
+ALTER TABLE services ADD COLUMN needs_redeploy BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1 @@
+ALTER TABLE services ADD COLUMN health_check JSONB;
@@ -0,0 +1 @@
+ALTER TABLE services ADD COLUMN ports JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,12 @@
+CREATE TABLE port_allocations (
+    host_port INTEGER NOT NULL,
+    protocol VARCHAR(16) NOT NULL,
+
+    service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    container_port INTEGER NOT NULL,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (host_port, protocol),
+    UNIQUE (service_id, container_port, protocol)
+);
@@ -0,0 +1 @@
+ALTER TABLE services ADD COLUMN resources JSONB;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_service_stats_sampled_at;
+DROP TABLE IF EXISTS service_stats;
@@ -0,0 +1,14 @@
+CREATE TABLE service_stats (
+    service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    sampled_at TIMESTAMPTZ NOT NULL,
+
+    cpu_percent DOUBLE PRECISION NOT NULL,
+    memory_usage BIGINT NOT NULL,
+    memory_limit BIGINT NOT NULL,
+    network_rx BIGINT NOT NULL,
+    network_tx BIGINT NOT NULL,
+
+    PRIMARY KEY (service_id, sampled_at)
+);
+
+CREATE INDEX idx_service_stats_sampled_at ON service_stats(sampled_at);
@@ -0,0 +1 @@
+ALTER TABLE services ADD COLUMN restart_policy JSONB;
@@ -6,30 +6,52 @@ import (
 	"time"
 )
 
+// LogStream is the output stream a log entry was written to.
+type LogStream string
+
+const (
+	// LogStreamStdout is the standard output stream.
+	LogStreamStdout LogStream = "stdout"
+	// LogStreamStderr is the standard error stream.
+	LogStreamStderr LogStream = "stderr"
+)
+
 // LogEntry represents a single log entry with a timestamp.
 type LogEntry struct {
-	// Timestamp is the time when the log entry was created (from Docker).
-	Timestamp time.Time `json:"timestamp"`
+	// Timestamp is the time when the log entry was created (from Docker). It
+	// is omitted when timestamps weren't requested.
+	Timestamp time.Time `json:"timestamp,omitzero"`
+	// Stream is the output stream the entry was written to.
+	Stream LogStream `json:"stream"`
 	// Content is the actual log message.
 	Content string `json:"content"`
 }
 
 // LogsWriter is a custom writer that processes log data and sends it to a channel.
 type LogsWriter struct {
-	channel chan<- LogEntry
-	buffer  bytes.Buffer
+	channel    chan<- LogEntry
+	buffer     bytes.Buffer
+	stream     LogStream
+	timestamps bool
 }
 
-// NewLogsWriter creates a new LogsWriter that sends log entries to the provided channel.
-func NewLogsWriter(channel chan<- LogEntry) *LogsWriter {
-	return &LogsWriter{channel: channel}
+// NewLogsWriter creates a new LogsWriter that sends log entries of the given
+// stream to the provided channel. If `timestamps` is set, every line is
+// expected to be prefixed with its timestamp (as requested from Docker with
+// `Timestamps`).
+func NewLogsWriter(channel chan<- LogEntry, stream LogStream, timestamps bool) *LogsWriter {
+	return &LogsWriter{channel: channel, stream: stream, timestamps: timestamps}
 }
 
 // parseLine parses a single line of log data into a LogEntry.
-func parseLine(line string) (*LogEntry, error) {
+func parseLine(line string, timestamps bool) (*LogEntry, error) {
 	line = strings.TrimSpace(line)
 	line = strings.TrimSuffix(line, "\n")
 
+	if !timestamps {
+		return &LogEntry{Content: line}, nil
+	}
+
 	var content string
 	var timestamp time.Time
 	var err error
@@ -65,10 +87,11 @@ func (w *LogsWriter) Write(data []byte) (int, error) {
 			break
 		}
 
-		entry, err := parseLine(line)
+		entry, err := parseLine(line, w.timestamps)
 		if err != nil {
 			return 0, err
 		}
+		entry.Stream = w.stream
 		w.channel <- *entry
 	}
 
@@ -81,10 +104,11 @@ func (w *LogsWriter) FlushRemaining() error {
 		return nil
 	}
 
-	entry, err := parseLine(w.buffer.String())
-	if err == nil {
+	entry, err := parseLine(w.buffer.String(), w.timestamps)
+	if err != nil {
 		return err
 	}
+	entry.Stream = w.stream
 
 	w.channel <- *entry
 	return nil
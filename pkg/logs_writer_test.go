@@ -0,0 +1,79 @@
+package pkg
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLogsWriter(t *testing.T) {
+	timestamp := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	tests := []struct {
+		name       string
+		stream     LogStream
+		timestamps bool
+		writes     []string
+		want       []LogEntry
+	}{
+		{
+			name:   "stdout lines",
+			stream: LogStreamStdout,
+			writes: []string{"first\nsecond\n"},
+			want: []LogEntry{
+				{Stream: LogStreamStdout, Content: "first"},
+				{Stream: LogStreamStdout, Content: "second"},
+			},
+		},
+		{
+			name:   "stderr lines",
+			stream: LogStreamStderr,
+			writes: []string{"error\n", "warning\n"},
+			want: []LogEntry{
+				{Stream: LogStreamStderr, Content: "error"},
+				{Stream: LogStreamStderr, Content: "warning"},
+			},
+		},
+		{
+			name:       "timestamped lines",
+			stream:     LogStreamStdout,
+			timestamps: true,
+			writes:     []string{timestamp.Format(time.RFC3339Nano) + " hello world\n"},
+			want: []LogEntry{
+				{Timestamp: timestamp, Stream: LogStreamStdout, Content: "hello world"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			channel := make(chan LogEntry, 16)
+			writer := NewLogsWriter(channel, test.stream, test.timestamps)
+			for _, write := range test.writes {
+				if _, err := writer.Write([]byte(write)); err != nil {
+					t.Fatalf("Write(%q) error = %v", write, err)
+				}
+			}
+			if err := writer.FlushRemaining(); err != nil {
+				t.Fatalf("FlushRemaining() error = %v", err)
+			}
+			close(channel)
+
+			got := make([]LogEntry, 0, len(channel))
+			for entry := range channel {
+				got = append(got, entry)
+			}
+			if !slices.EqualFunc(got, test.want, func(a, b LogEntry) bool {
+				return a.Timestamp.Equal(b.Timestamp) && a.Stream == b.Stream && a.Content == b.Content
+			}) {
+				t.Errorf("entries = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLogsWriterInvalidTimestamp(t *testing.T) {
+	writer := NewLogsWriter(make(chan LogEntry, 1), LogStreamStdout, true)
+	if _, err := writer.Write([]byte("yesterday hello\n")); err == nil {
+		t.Error("Write() error = nil, want a timestamp parse error")
+	}
+}